@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	gomail "gopkg.in/mail.v2"
+	"gorm.io/gorm"
+)
+
+var errEventFull = errors.New("Event sudah penuh")
+
+// kurangi sisa kapasitas secara atomik, gagal kalau kursi tidak cukup
+func reserveSeats(tx *gorm.DB, eventID uint, seats int) error {
+	result := tx.Model(&models.Event{}).
+		Where("id = ? AND remaining_capacity >= ?", eventID, seats).
+		UpdateColumn("remaining_capacity", gorm.Expr("remaining_capacity - ?", seats))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errEventFull
+	}
+	return nil
+}
+
+// ubah status pendaftaran pending jadi approved / rejected
+func updateRegistrationStatus(eventID, registrationID uint, status, reason string) (int, error) {
+	var event models.Event
+	if err := database.DB.First(&event, eventID).Error; err != nil {
+		return http.StatusNotFound, errors.New("Event tidak ditemukan")
+	}
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var registration models.Registration
+	if err := tx.Where("id = ? AND event_id = ?", registrationID, eventID).First(&registration).Error; err != nil {
+		tx.Rollback()
+		return http.StatusNotFound, errors.New("Pendaftaran tidak ditemukan")
+	}
+
+	if registration.Status != "pending" {
+		tx.Rollback()
+		return http.StatusConflict, fmt.Errorf("Pendaftaran sudah berstatus %s", registration.Status)
+	}
+
+	if status == "approved" {
+		if err := reserveSeats(tx, event.ID, 1); err != nil {
+			tx.Rollback()
+			if errors.Is(err, errEventFull) {
+				return http.StatusBadRequest, err
+			}
+			return http.StatusInternalServerError, errors.New("Gagal memperbarui kapasitas event")
+		}
+	}
+
+	registration.Status = status
+	registration.StatusReason = reason
+	if err := tx.Save(&registration).Error; err != nil {
+		tx.Rollback()
+		return http.StatusInternalServerError, errors.New("Gagal memperbarui status pendaftaran")
+	}
+
+	if err := sendRegistrationStatusEmail(registration.Email, registration.Name, event.Name, status, reason); err != nil {
+		tx.Rollback()
+		return http.StatusInternalServerError, errors.New("Gagal mengirim email status pendaftaran")
+	}
+
+	if status == "approved" {
+		if strings.ToLower(event.Price) != "free" && registration.PaymentMethod != "" {
+			if err := sendPaymentConfirmationEmail(registration.Email, registration.Name, event.Name, event.Description, formatEventDate(event), event.Location, registration.PaymentMethod); err != nil {
+				tx.Rollback()
+				return http.StatusInternalServerError, errors.New("Gagal mengirim email konfirmasi pembayaran")
+			}
+		}
+
+		if err := sendEmail(registration.Email, registration.Name, registration.PhoneNumber, registration.Job, event.Name, event.Location, formatEventDate(event), event.Description, event.Mode, event.Link, event.Address, registration.TicketCode); err != nil {
+			tx.Rollback()
+			return http.StatusInternalServerError, errors.New("Gagal mengirim email konfirmasi pendaftaran")
+		}
+	}
+
+	tx.Commit()
+
+	if status == "approved" {
+		if err := UpdatePopularityScore(event.ID); err != nil {
+			log.Printf("Gagal memperbarui popularity score: %v", err)
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+func handleRegistrationStatus(c *gin.Context, status string) {
+	event, _, ok := findManagedEventParam(c, "event_id")
+	if !ok {
+		return
+	}
+
+	registrationID, err := strconv.Atoi(c.Param("registration_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration ID"})
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	// alasan opsional, body kosong tetap diterima
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		return
+	}
+
+	if code, err := updateRegistrationStatus(event.ID, uint(registrationID), status, input.Reason); err != nil {
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Status pendaftaran berhasil diperbarui", "status": status})
+}
+
+func handleBulkRegistrationStatus(c *gin.Context, status string) {
+	event, _, ok := findManagedEventParam(c, "event_id")
+	if !ok {
+		return
+	}
+
+	var input struct {
+		RegistrationIDs []uint `json:"registration_ids"`
+		Reason          string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || len(input.RegistrationIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		return
+	}
+
+	var results []gin.H
+	for _, registrationID := range input.RegistrationIDs {
+		if _, err := updateRegistrationStatus(event.ID, registrationID, status, input.Reason); err != nil {
+			results = append(results, gin.H{"id": registrationID, "error": err.Error()})
+			continue
+		}
+		results = append(results, gin.H{"id": registrationID, "status": status})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// setujui pendaftaran
+func ApproveRegistration(c *gin.Context) {
+	handleRegistrationStatus(c, "approved")
+}
+
+// tolak pendaftaran
+func RejectRegistration(c *gin.Context) {
+	handleRegistrationStatus(c, "rejected")
+}
+
+func BulkApproveRegistrations(c *gin.Context) {
+	handleBulkRegistrationStatus(c, "approved")
+}
+
+func BulkRejectRegistrations(c *gin.Context) {
+	handleBulkRegistrationStatus(c, "rejected")
+}
+
+func sendRegistrationStatusEmail(to, name, eventName, status, reason string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", os.Getenv("SMTP_USER"))
+	m.SetHeader("To", to)
+
+	var subject, message, color string
+	switch status {
+	case "pending":
+		subject = "Pendaftaran Diterima - " + eventName
+		message = "Pendaftaran Anda telah kami terima dan sedang menunggu persetujuan penyelenggara. Kami akan mengabari Anda melalui email setelah pendaftaran diproses."
+		color = "#856404"
+	case "approved":
+		subject = "Pendaftaran Disetujui - " + eventName
+		message = "Selamat! Pendaftaran Anda telah disetujui oleh penyelenggara. Detail event akan dikirim pada email konfirmasi pendaftaran."
+		color = "#28a745"
+	default:
+		subject = "Pendaftaran Ditolak - " + eventName
+		message = "Mohon maaf, pendaftaran Anda belum dapat disetujui oleh penyelenggara."
+		color = "#dc3545"
+	}
+	m.SetHeader("Subject", subject)
+
+	var reasonTemplate string
+	if reason != "" {
+		reasonTemplate = fmt.Sprintf(`
+			<div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 15px 0;">
+				<p style="color: #666; margin: 0;"><strong>Catatan dari penyelenggara:</strong> %s</p>
+			</div>
+		`, reason)
+	}
+
+	body := fmt.Sprintf(`
+		<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto;">
+			<h2 style="color: #333;">Halo, %s!</h2>
+
+			<div style="background-color: #f8f9fa; padding: 20px; border-radius: 5px; margin: 15px 0;">
+				<h3 style="color: #007bff; margin-top: 0;">%s</h3>
+				<p style="color: %s;">%s</p>
+			</div>
+
+			%s
+
+			<div style="margin-top: 20px; padding-top: 20px; border-top: 1px solid #eee;">
+				<p style="color: #666; font-size: 14px;">
+					Jika Anda memiliki pertanyaan, silakan hubungi tim support kami di:<br>
+					Email: anjarriho081@gmail.com<br>
+					WhatsApp: +62 890 3333 4444
+				</p>
+			</div>
+		</div>
+	`, name, eventName, color, message, reasonTemplate)
+
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer("smtp.gmail.com", 587, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"))
+	if err := d.DialAndSend(m); err != nil {
+		return err
+	}
+	return nil
+}
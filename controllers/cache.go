@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control per jenis endpoint. daftar & detail event cepat berubah (sisa kursi, rating)
+// jadi umurnya pendek dan harus divalidasi ulang, kategori dan lokasi jarang berubah
+const (
+	eventListCacheControl   = "public, max-age=30, stale-while-revalidate=30"
+	eventDetailCacheControl = "public, max-age=10, must-revalidate"
+	privateCacheControl     = "private, no-cache"
+	taxonomyCacheControl    = "public, max-age=300"
+)
+
+// tabel yang isinya ikut membentuk daftar event (termasuk relasi ?include=)
+var eventListTables = []string{"events", "ratings", "categories", "locations", "tags", "sessions", "event_tags", "event_categories"}
+
+// tabel pendamping detail event, perubahan di sini dianggap mengubah semua event
+var eventDetailTables = []string{"categories", "locations", "venues", "venue_rooms", "tags", "sessions", "event_tags", "event_categories"}
+
+// perubahan terakhir di tabel-tabel ini menurut table_changes, yang diisi trigger untuk
+// insert, update, delete maupun hapus permanen
+func tablesLastModified(tables ...string) time.Time {
+	var modified *time.Time
+	database.DB.Raw("SELECT MAX(changed_at) FROM table_changes WHERE table_name IN ?", tables).Scan(&modified)
+	if modified == nil {
+		return time.Time{}
+	}
+	return *modified
+}
+
+// perubahan terakhir satu event beserta ratingnya dan tabel pendampingnya
+func eventLastModified(eventID uint) time.Time {
+	var modified *time.Time
+	database.DB.Raw(`SELECT GREATEST(
+		(SELECT updated_at FROM events WHERE id = ?),
+		(SELECT MAX(updated_at) FROM ratings WHERE event_id = ?),
+		(SELECT MAX(changed_at) FROM table_changes WHERE table_name IN ?))`, eventID, eventID, eventDetailTables).Scan(&modified)
+	if modified == nil {
+		return time.Time{}
+	}
+	return *modified
+}
+
+func setCacheHeaders(c *gin.Context, modified time.Time, cacheControl string) {
+	c.Header("Cache-Control", cacheControl)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// If-Modified-Since dicek sebelum response dibangun supaya query lainnya tidak perlu jalan.
+// kalau ada If-None-Match, yang dipakai ETag (dicek di respondCached)
+func notModifiedSince(c *gin.Context, modified time.Time, cacheControl string) bool {
+	header := c.GetHeader("If-Modified-Since")
+	if header == "" || c.GetHeader("If-None-Match") != "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil || modified.Truncate(time.Second).After(since) {
+		return false
+	}
+
+	setCacheHeaders(c, modified, cacheControl)
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// tulis body dengan ETag dari hash isinya (diawali prefix kalau ada) dan Last-Modified.
+// kalau If-None-Match cocok dibalas 304 tanpa body
+func respondCached(c *gin.Context, body interface{}, modified time.Time, cacheControl, etagPrefix string) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+		return
+	}
+
+	sum := sha1.Sum(data)
+	etag := fmt.Sprintf(`"%s%s"`, etagPrefix, hex.EncodeToString(sum[:8]))
+	c.Header("ETag", etag)
+	setCacheHeaders(c, modified, cacheControl)
+
+	if header := c.GetHeader("If-None-Match"); header != "" && (strings.TrimSpace(header) == "*" || etagMatches(header, etag)) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
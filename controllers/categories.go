@@ -1,12 +1,124 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"backend-event/models"
 	"backend-event/database"
 	"github.com/gin-gonic/gin"
 )
 
+var categoryColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type categoryNode struct {
+	models.Category
+	EventCount      int64           `json:"event_count"`
+	TotalEventCount int64           `json:"total_event_count"`
+	Children        []*categoryNode `json:"children"`
+}
+
+// semua kategori, urut sesuai sort_order lalu nama
+func loadCategories() ([]models.Category, error) {
+	var categories []models.Category
+	err := database.DB.Order("sort_order, name").Find(&categories).Error
+	return categories, err
+}
+
+// kategori berdasarkan id atau slug
+func findCategory(value string) (models.Category, error) {
+	var category models.Category
+	if id, err := strconv.Atoi(value); err == nil && database.DB.First(&category, id).Error == nil {
+		return category, nil
+	}
+	return category, database.DB.Where("slug = ?", value).First(&category).Error
+}
+
+// id kategori beserta semua turunannya
+func categoryDescendantIDs(categories []models.Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	seen := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// cek data kategori sebelum disimpan, slug kosong dibuat dari nama
+func prepareCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return errors.New("Name is required")
+	}
+	if category.Color != "" && !categoryColorPattern.MatchString(category.Color) {
+		return errors.New("Color must be a hex value like #1A2B3C")
+	}
+
+	if category.ParentID != nil && *category.ParentID == 0 {
+		category.ParentID = nil
+	}
+	if category.ParentID != nil {
+		categories, err := loadCategories()
+		if err != nil {
+			return err
+		}
+		parentExists := false
+		for _, existing := range categories {
+			if existing.ID == *category.ParentID {
+				parentExists = true
+			}
+		}
+		if !parentExists {
+			return errors.New("Parent category not found")
+		}
+		if category.ID != 0 {
+			for _, id := range categoryDescendantIDs(categories, category.ID) {
+				if id == *category.ParentID {
+					return errors.New("Parent cannot be the category itself or one of its descendants")
+				}
+			}
+		}
+	}
+
+	taken := func(slug string) bool {
+		var count int64
+		database.DB.Unscoped().Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, category.ID).Count(&count)
+		return count > 0
+	}
+
+	if category.Slug != "" {
+		category.Slug = models.Slugify(category.Slug)
+		if category.Slug == "" {
+			return errors.New("Invalid slug")
+		}
+		if taken(category.Slug) {
+			return errors.New("Slug already used by another category")
+		}
+		return nil
+	}
+
+	base := models.Slugify(category.Name)
+	if base == "" {
+		base = "kategori"
+	}
+	category.Slug = models.UniqueSlug(base, taken)
+	return nil
+}
+
 func CreateCategory(c *gin.Context) {
 	var category models.Category
 	if err := c.ShouldBindJSON(&category); err != nil {
@@ -14,6 +126,12 @@ func CreateCategory(c *gin.Context) {
 		return
 	}
 
+	category.ID = 0
+	if err := prepareCategory(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := database.DB.Create(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
 		return
@@ -22,26 +140,63 @@ func CreateCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Category created", "data": category})
 }
 
+// daftar kategori, ?parent_id=root untuk kategori teratas atau ?parent_id=<id> untuk anaknya
 func GetCategories(c *gin.Context) {
+	modified := tablesLastModified("categories")
+	if notModifiedSince(c, modified, taxonomyCacheControl) {
+		return
+	}
+
+	query := database.DB.Order("sort_order, name")
+	if parentID := c.Query("parent_id"); parentID == "root" {
+		query = query.Where("parent_id IS NULL")
+	} else if parentID != "" {
+		query = query.Where("parent_id = ?", parentID)
+	}
+
 	var categories []models.Category
-	if err := database.DB.Find(&categories).Error; err != nil {
+	if err := query.Find(&categories).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
 		return
 	}
 
-	c.JSON(http.StatusOK, categories)
+	respondCached(c, categories, modified, taxonomyCacheControl, "")
 }
 
+// kategori berdasarkan id atau slug, beserta anak dan jalur induknya
 func GetCategoryByID(c *gin.Context) {
-    categoryID := c.Param("id") 
-    var category models.Category
+	category, err := findCategory(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	categories, err := loadCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+
+	byID := make(map[uint]models.Category, len(categories))
+	children := []models.Category{}
+	for _, existing := range categories {
+		byID[existing.ID] = existing
+		if existing.ParentID != nil && *existing.ParentID == category.ID {
+			children = append(children, existing)
+		}
+	}
 
-    if err := database.DB.First(&category, categoryID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
-        return
-    }
+	path := []models.Category{category}
+	for parentID := category.ParentID; parentID != nil && len(path) <= len(categories); {
+		parent, ok := byID[*parentID]
+		if !ok {
+			break
+		}
+		path = append([]models.Category{parent}, path...)
+		parentID = parent.ParentID
+	}
 
-    c.JSON(http.StatusOK, gin.H{"data": category})
+	c.JSON(http.StatusOK, gin.H{"data": category, "children": children, "path": path})
 }
 
 func UpdateCategory(c *gin.Context) {
@@ -52,11 +207,18 @@ func UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	categoryID := category.ID
 	if err := c.ShouldBindJSON(&category); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
+	category.ID = categoryID
+	if err := prepareCategory(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := database.DB.Save(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
 		return
@@ -73,6 +235,18 @@ func DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	if !reassignEvents(c, "category_id", category.ID, func(id uint) bool {
+		return database.DB.First(&models.Category{}, id).Error == nil
+	}, "event_categories") {
+		return
+	}
+
+	// anak kategori naik ke induk kategori yang dihapus
+	if err := database.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Update("parent_id", category.ParentID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move child categories"})
+		return
+	}
+
 	if err := database.DB.Delete(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
 		return
@@ -81,3 +255,67 @@ func DeleteCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
 }
 
+// pohon kategori beserta jumlah event per kategori. total_event_count ikut menghitung
+// event di kategori turunan, event yang ada di beberapa kategori turunan dihitung sekali
+func GetCategoryTree(c *gin.Context) {
+	categories, err := loadCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+
+	var pairs []struct {
+		EventID    uint
+		CategoryID uint
+	}
+	if err := database.DB.Raw(`SELECT id AS event_id, category_id FROM events
+		WHERE deleted_at IS NULL AND visibility = ? AND publication_status = ?
+		UNION
+		SELECT event_categories.event_id, event_categories.category_id FROM event_categories
+		JOIN events ON events.id = event_categories.event_id
+		WHERE events.deleted_at IS NULL AND events.visibility = ? AND events.publication_status = ?`,
+		"public", models.PublicationPublished, "public", models.PublicationPublished).Scan(&pairs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count events"})
+		return
+	}
+
+	nodes := make(map[uint]*categoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &categoryNode{Category: category, Children: []*categoryNode{}}
+	}
+
+	totals := make(map[uint]map[uint]bool)
+	for _, pair := range pairs {
+		node, ok := nodes[pair.CategoryID]
+		if !ok {
+			continue
+		}
+		node.EventCount++
+		for depth := 0; node != nil && depth <= len(categories); depth++ {
+			if totals[node.ID] == nil {
+				totals[node.ID] = make(map[uint]bool)
+			}
+			totals[node.ID][pair.EventID] = true
+			if node.ParentID == nil {
+				break
+			}
+			node = nodes[*node.ParentID]
+		}
+	}
+
+	roots := []*categoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		node.TotalEventCount = int64(len(totals[category.ID]))
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	c.JSON(http.StatusOK, roots)
+}
+
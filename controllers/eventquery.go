@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/serializers"
+	"math"
+	"time"
+)
+
+type eventRatingStats struct {
+	EventID       uint
+	AverageRating float64
+	UniqueRaters  int64
+}
+
+// data pendamping daftar event (kota, kategori, rating). tiap jenis diambil dengan satu query
+// untuk semua event sekaligus, jadi jumlah query tidak bertambah mengikuti jumlah event
+type eventListing struct {
+	cities     map[uint]string
+	categories map[uint]string
+	ratings    map[uint]eventRatingStats
+}
+
+func eventIDs(events []models.Event) []uint {
+	ids := make([]uint, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+// rata-rata rating (dibulatkan 2 desimal) dan jumlah pemberi rating per event
+func loadEventRatings(ids []uint) (map[uint]eventRatingStats, error) {
+	ratings := make(map[uint]eventRatingStats, len(ids))
+	if len(ids) == 0 {
+		return ratings, nil
+	}
+
+	var rows []eventRatingStats
+	err := database.DB.Model(&models.Rating{}).
+		Select("event_id, COALESCE(AVG(rating), 0) AS average_rating, COUNT(DISTINCT user_id) AS unique_raters").
+		Where("event_id IN ?", ids).
+		Group("event_id").
+		Scan(&rows).Error
+	if err != nil {
+		return ratings, err
+	}
+	for _, row := range rows {
+		row.AverageRating = math.Round(row.AverageRating*100) / 100
+		ratings[row.EventID] = row
+	}
+	return ratings, nil
+}
+
+// sesi semua event dimuat dengan satu query ke event.Sessions
+func loadEventSessions(events []models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("event_id IN ?", eventIDs(events)).Order("starts_at, id").Find(&sessions).Error; err != nil {
+		return err
+	}
+	byEvent := make(map[uint][]models.Session)
+	for _, session := range sessions {
+		byEvent[session.EventID] = append(byEvent[session.EventID], session)
+	}
+	for i := range events {
+		events[i].Sessions = byEvent[events[i].ID]
+	}
+	return nil
+}
+
+// hanya relasi yang dibutuhkan shape yang di-query
+func loadEventListing(events []models.Event, shape responseShape) (eventListing, error) {
+	listing := eventListing{
+		cities:     make(map[uint]string),
+		categories: make(map[uint]string),
+		ratings:    make(map[uint]eventRatingStats),
+	}
+	if len(events) == 0 {
+		return listing, nil
+	}
+
+	if shape.needs("rating_summary") {
+		ratings, err := loadEventRatings(eventIDs(events))
+		if err != nil {
+			return listing, err
+		}
+		listing.ratings = ratings
+	}
+	if shape.needs("sessions") {
+		if err := loadEventSessions(events); err != nil {
+			return listing, err
+		}
+	}
+
+	var locationIDs, categoryIDs []uint
+	for _, event := range events {
+		if event.LocationID != 0 {
+			locationIDs = append(locationIDs, event.LocationID)
+		}
+		categoryIDs = append(categoryIDs, event.CategoryID)
+	}
+
+	if shape.needs("location") && len(locationIDs) > 0 {
+		var locations []models.Location
+		if err := database.DB.Select("id, city").Where("id IN ?", locationIDs).Find(&locations).Error; err != nil {
+			return listing, err
+		}
+		for _, location := range locations {
+			listing.cities[location.ID] = location.City
+		}
+	}
+
+	if shape.needs("category") {
+		var categories []models.Category
+		if err := database.DB.Select("id, name").Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+			return listing, err
+		}
+		for _, category := range categories {
+			listing.categories[category.ID] = category.Name
+		}
+	}
+	return listing, nil
+}
+
+func (l eventListing) meta(event models.Event) serializers.EventMeta {
+	rating := l.ratings[event.ID]
+	return serializers.EventMeta{
+		City:          l.cities[event.LocationID],
+		Category:      l.categories[event.CategoryID],
+		AverageRating: rating.AverageRating,
+		UniqueRaters:  rating.UniqueRaters,
+	}
+}
+
+// ringkasan daftar event, data pendampingnya dimuat sekaligus
+func serializeEvents(events []models.Event, shape responseShape) ([]serializers.Event, error) {
+	listing, err := loadEventListing(events, shape)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	result := make([]serializers.Event, 0, len(events))
+	for _, event := range events {
+		result = append(result, serializers.NewEvent(event, listing.meta(event), now))
+	}
+	return result, nil
+}
+
+// venue event beserta ruangannya, nil kalau event tidak memakai venue
+func loadEventVenue(event models.Event) *models.Venue {
+	if event.VenueID == nil {
+		return nil
+	}
+	var venue models.Venue
+	if err := database.DB.Preload("Rooms").First(&venue, *event.VenueID).Error; err != nil {
+		return nil
+	}
+	return &venue
+}
+
+// tampilan event untuk organizer (preview, hasil create / update). kalau data pendamping
+// gagal dimuat, response tetap dibuat tanpa data tersebut dan error-nya dikembalikan
+func managedEventResponse(event models.Event, sessions []models.Session) (serializers.ManagedEvent, error) {
+	// sesi sudah dimuat handler, tidak perlu di-query lagi
+	shape := fullShape(serializers.EventDetailRelations)
+	shape.include["sessions"] = false
+	event.Sessions = sessions
+
+	listing, err := loadEventListing([]models.Event{event}, shape)
+	return serializers.NewManagedEvent(event, listing.meta(event), loadEventVenue(event), time.Now()), err
+}
+
+// daftar event milik organizer
+func serializeManagedEvents(events []models.Event, shape responseShape) ([]serializers.ManagedEvent, error) {
+	listing, err := loadEventListing(events, shape)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	result := make([]serializers.ManagedEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, serializers.NewManagedEvent(event, listing.meta(event), nil, now))
+	}
+	return result, nil
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/serializers"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// listingDriver is a database/sql driver that answers every query against
+// the events table with the same set of events, registrations with one
+// registration per event, COUNT(*) with the number of events and everything
+// else with no rows. It lets the listing handlers run without a database.
+type listingDriver struct {
+	mu     sync.Mutex
+	events int
+}
+
+func (d *listingDriver) Open(string) (driver.Conn, error) { return &listingConn{driver: d}, nil }
+
+func (d *listingDriver) size() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.events
+}
+
+type listingConn struct{ driver *listingDriver }
+
+func (c *listingConn) Prepare(query string) (driver.Stmt, error) {
+	return &listingStmt{conn: c, query: query}, nil
+}
+func (c *listingConn) Close() error              { return nil }
+func (c *listingConn) Begin() (driver.Tx, error) { return listingTx{}, nil }
+
+func (c *listingConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (c *listingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	n := c.driver.size()
+	lower := strings.ToLower(query)
+	start := time.Now().Add(24 * time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	switch {
+	case strings.HasPrefix(lower, "select count("):
+		return &listingRows{columns: []string{"count"}, values: [][]driver.Value{{int64(n)}}}, nil
+	case strings.Contains(lower, `from "events"`):
+		rows := &listingRows{columns: []string{"id", "name", "location_id", "category_id", "status", "visibility", "publication_status", "starts_at", "ends_at", "timezone"}}
+		for i := 1; i <= n; i++ {
+			rows.values = append(rows.values, []driver.Value{int64(i), fmt.Sprintf("Event %d", i), int64(i), int64(i), "upcoming", "public", models.PublicationPublished, start, end, "Asia/Jakarta"})
+		}
+		return rows, nil
+	case strings.Contains(lower, `from "registrations"`):
+		rows := &listingRows{columns: []string{"id", "user_id", "event_id", "status"}}
+		if strings.Contains(lower, `select "event_id"`) {
+			// pluck id event yang sudah didaftar
+			return &listingRows{columns: []string{"event_id"}}, nil
+		}
+		for i := 1; i <= n; i++ {
+			rows.values = append(rows.values, []driver.Value{int64(i), int64(1), int64(i), "approved"})
+		}
+		return rows, nil
+	}
+	return &listingRows{}, nil
+}
+
+type listingStmt struct {
+	conn  *listingConn
+	query string
+}
+
+func (s *listingStmt) Close() error  { return nil }
+func (s *listingStmt) NumInput() int { return -1 }
+func (s *listingStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (s *listingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, nil)
+}
+
+type listingTx struct{}
+
+func (listingTx) Commit() error   { return nil }
+func (listingTx) Rollback() error { return nil }
+
+type listingRows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *listingRows) Columns() []string { return r.columns }
+func (r *listingRows) Close() error      { return nil }
+func (r *listingRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+var (
+	listingDB     *listingDriver
+	listingDBOnce sync.Once
+)
+
+// ganti database.DB dengan database palsu berisi n event, kembalikan penghitung query
+// yang dicatat lewat callback gorm
+func useListingDB(t testing.TB, n int) *int {
+	t.Helper()
+	listingDBOnce.Do(func() {
+		listingDB = &listingDriver{}
+		sql.Register("listing", listingDB)
+	})
+	listingDB.mu.Lock()
+	listingDB.events = n
+	listingDB.mu.Unlock()
+
+	conn, err := sql.Open("listing", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := new(int)
+	count := func(*gorm.DB) { *queries++ }
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_queries", count); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:count_queries", count); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:count_queries", count); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return queries
+}
+
+var listingSizes = []int{1, 50, 500}
+
+// jumlah query untuk tiap ukuran hasil harus sama
+func assertConstantQueries(t *testing.T, run func(t *testing.T, n int) int) {
+	counts := make(map[int]int)
+	for _, n := range listingSizes {
+		counts[n] = run(t, n)
+		if counts[n] == 0 {
+			t.Fatalf("%d events: no queries counted", n)
+		}
+	}
+	t.Logf("queries per result size: %v", counts)
+	for _, n := range listingSizes[1:] {
+		if counts[n] != counts[listingSizes[0]] {
+			t.Fatalf("query count depends on result size: %v", counts)
+		}
+	}
+}
+
+func listingEvents(n int) []models.Event {
+	events := make([]models.Event, n)
+	for i := range events {
+		events[i] = models.Event{ID: uint(i + 1), LocationID: uint(i + 1), CategoryID: uint(i + 1)}
+	}
+	return events
+}
+
+func TestLoadEventListingQueryCount(t *testing.T) {
+	shape := fullShape(serializers.EventDetailRelations)
+	assertConstantQueries(t, func(t *testing.T, n int) int {
+		queries := useListingDB(t, n)
+		if _, err := loadEventListing(listingEvents(n), shape); err != nil {
+			t.Fatal(err)
+		}
+		return *queries
+	})
+}
+
+// panggil handler daftar event dengan database palsu, kembalikan jumlah query
+func runListingHandler(t *testing.T, n int, handler gin.HandlerFunc, target string, user *models.User) int {
+	t.Helper()
+	queries := useListingDB(t, n)
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	if user != nil {
+		c.Set("user", *user)
+	}
+
+	handler(c)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("%s with %d events: status %d: %s", target, n, recorder.Code, recorder.Body.String())
+	}
+	return *queries
+}
+
+func TestGetAllEventsQueryCount(t *testing.T) {
+	assertConstantQueries(t, func(t *testing.T, n int) int {
+		return runListingHandler(t, n, GetAllEvents, "/event?per_page=100&include=location,category,categories,tags,rating_summary,sessions", nil)
+	})
+}
+
+func TestGetPopularEventsQueryCount(t *testing.T) {
+	assertConstantQueries(t, func(t *testing.T, n int) int {
+		return runListingHandler(t, n, GetPopularEvents, "/events/popular?per_page=100", nil)
+	})
+}
+
+func TestGetRegisteredEventsQueryCount(t *testing.T) {
+	assertConstantQueries(t, func(t *testing.T, n int) int {
+		return runListingHandler(t, n, GetRegisteredEvents, "/events/registered", &models.User{ID: 1})
+	})
+}
+
+func TestGetUnregisteredEventsQueryCount(t *testing.T) {
+	assertConstantQueries(t, func(t *testing.T, n int) int {
+		return runListingHandler(t, n, GetUnregisteredEvents, "/events/unregistered?per_page=100", &models.User{ID: 1})
+	})
+}
+
+func BenchmarkLoadEventListing(b *testing.B) {
+	shape := fullShape(serializers.EventDetailRelations)
+	for _, n := range listingSizes {
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			queries := useListingDB(b, n)
+			events := listingEvents(n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := loadEventListing(events, shape); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(*queries)/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -3,16 +3,21 @@ package controllers
 import (
 	"backend-event/database"
 	"backend-event/models"
+	"backend-event/scheduler"
+	"backend-event/serializers"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"math"
+	"gorm.io/gorm"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func CreateEvent(c *gin.Context) {
+// baca data event dari form, dipakai untuk event tunggal maupun event berulang
+func eventFromForm(c *gin.Context) (models.Event, []models.Session, bool) {
 	var event models.Event
 
 	file, err := c.FormFile("photo")
@@ -24,13 +29,13 @@ func CreateEvent(c *gin.Context) {
 		if _, err := os.Stat("./uploads"); os.IsNotExist(err) {
 			if err := os.Mkdir("./uploads", os.ModePerm); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create uploads directory"})
-				return
+				return event, nil, false
 			}
 		}
 
 		if err := c.SaveUploadedFile(file, uploadPath); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload photo"})
-			return
+			return event, nil, false
 		}
 
 		event.Photo = fmt.Sprintf("/uploads/%s", file.Filename)
@@ -48,11 +53,36 @@ func CreateEvent(c *gin.Context) {
 	if event.Price == "" {
 		event.Price = "Free"
 	}
+	event.RequiresApproval, _ = strconv.ParseBool(c.PostForm("requires_approval"))
+	event.Visibility = c.PostForm("visibility")
+	if event.Visibility == "" {
+		event.Visibility = "public"
+	}
+	if !isValidVisibility(event.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+		return event, nil, false
+	}
+	event.AccessCode = c.PostForm("access_code")
+	event.TransferPolicy = c.PostForm("transfer_policy")
+	if event.TransferPolicy == "" {
+		event.TransferPolicy = "disabled"
+	}
+	if !isValidTransferPolicy(event.TransferPolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer policy"})
+		return event, nil, false
+	}
+	event.TransferDeadline = c.PostForm("transfer_deadline")
+	if event.TransferDeadline != "" {
+		if _, err := time.Parse("2006-01-02", event.TransferDeadline); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer deadline format"})
+			return event, nil, false
+		}
+	}
 
 	capacity, err := strconv.Atoi(c.PostForm("capacity"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid capacity"})
-		return
+		return event, nil, false
 	}
 	event.Capacity = capacity
 	event.RemainingCapacity = capacity
@@ -71,52 +101,66 @@ func CreateEvent(c *gin.Context) {
 			event.Address = c.PostForm("address")
 			if event.Address == "" {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Address is required when location is specified"})
-				return
+				return event, nil, false
 			}
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
-			return
+			return event, nil, false
 		}
 	}
 
 	categoryID, err := strconv.Atoi(c.PostForm("category_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
-		return
+		return event, nil, false
 	}
 	event.CategoryID = uint(categoryID)
 
+	if err := applyVenueFromForm(c, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return event, nil, false
+	}
+
 	if event.Mode == "online" && event.Link == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Link is required for online events"})
-		return
+		return event, nil, false
+	}
+
+	if err := bindEventSchedule(c, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return event, nil, false
+	}
+
+	event.Status = event.ComputeStatus(time.Now())
+
+	if err := bindEventPublication(c, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return event, nil, false
 	}
 
-	dateStart, err := time.Parse("2006-01-02", event.DateStart)
+	sessions, err := sessionsFromForm(c, event, nil)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
-		return
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return event, nil, false
 	}
 
-	var dateEnd time.Time
-	if event.DateEnd != "" {
-		dateEnd, err = time.Parse("2006-01-02", event.DateEnd)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-			return
-		}
-		if dateEnd.Before(dateStart) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "End date cannot be before start date"})
-			return
-		}
+	if event.Categories, err = categoriesFromForm(c, event.CategoryID, nil, 0); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return event, nil, false
 	}
 
-	currentDate := time.Now()
-	if currentDate.Before(dateStart) {
-		event.Status = "upcoming"
-	} else if currentDate.After(dateStart) && (event.DateEnd == "" || currentDate.Before(dateEnd)) {
-		event.Status = "ongoing"
-	} else if event.DateEnd != "" && currentDate.After(dateEnd) {
-		event.Status = "ended"
+	if event.Tags, _, err = tagsFromForm(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tags"})
+		return event, nil, false
+	}
+
+	return event, sessions, true
+}
+
+func CreateEvent(c *gin.Context) {
+	event, sessions, ok := eventFromForm(c)
+	if !ok {
+		return
 	}
 
 	if err := database.DB.Create(&event).Error; err != nil {
@@ -124,30 +168,8 @@ func CreateEvent(c *gin.Context) {
 		return
 	}
 
-	var sessions []models.Session
-	for i := 0; ; i++ {
-		date := c.PostForm(fmt.Sprintf("sessions[%d][date]", i))
-		sessionTime := c.PostForm(fmt.Sprintf("sessions[%d][time]", i))
-		speaker := c.PostForm(fmt.Sprintf("sessions[%d][speaker]", i))
-		location := c.PostForm(fmt.Sprintf("sessions[%d][location]", i))
-
-		if date == "" {
-			break
-		}
-
-		_, err := time.Parse("2006-01-02", date)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Format tanggal tidak valid untuk sesi %d", i)})
-			return
-		}
-
-		sessions = append(sessions, models.Session{
-			EventID:  event.ID,
-			Date:     date,
-			Time:     sessionTime,
-			Speaker:  speaker,
-			Location: location,
-		})
+	for i := range sessions {
+		sessions[i].EventID = event.ID
 	}
 
 	if len(sessions) > 0 {
@@ -157,288 +179,153 @@ func CreateEvent(c *gin.Context) {
 		}
 	}
 
+	scheduler.Wake()
+
+	response, err := managedEventResponse(event, sessions)
+	if err != nil {
+		log.Printf("Gagal memuat data pendamping event %d: %v", event.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Event and sessions created successfully",
-		"event":    event,
-		"sessions": sessions,
+		"event":    response,
+		"sessions": serializers.NewSessions(sessions),
 	})
 }
 
 // get semua event
 func GetAllEvents(c *gin.Context) {
-	var events []models.Event
-	if err := database.DB.Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+	shape, ok := parseResponseShape(c, serializers.EventRelations, eventListInclude...)
+	if !ok {
 		return
 	}
 
-	var response []struct {
-		ID                uint    `json:"id"`
-		Name              string  `json:"name"`
-		Description       string  `json:"description"`
-		DateStart         string  `json:"date_start"`
-		DateEnd           string  `json:"date_end"`
-		Time              string  `json:"time"`
-		Location          string  `json:"location"`
-		Address           string  `json:"address"`
-		Capacity          int     `json:"capacity"`
-		RemainingCapacity int     `json:"remaining_capacity"`
-		Photo             string  `json:"photo"`
-		Price             string  `json:"price"`
-		Category          string  `json:"category"`
-		Status            string  `json:"status"`
-		Mode              string  `json:"mode"`
-		AverageRating     float64 `json:"average_rating"`
-		UniqueRaters      int64   `json:"unique_raters"`
-	}
-
-	for _, event := range events {
-		var location models.Location
-		if event.LocationID != 0 {
-			if err := database.DB.First(&location, event.LocationID).Error; err != nil {
-				location.City = ""
-			}
-		} else {
-			location.City = "Online"
-		}
-
-		var category models.Category
-		if err := database.DB.First(&category, event.CategoryID).Error; err != nil {
-			category.Name = ""
-		}
+	modified := tablesLastModified(eventListTables...)
+	if notModifiedSince(c, modified, eventListCacheControl) {
+		return
+	}
 
-		var eventStatus string
-		dateStart, err := time.Parse("2006-01-02", event.DateStart)
-		if err != nil {
-			eventStatus = "unknown"
-		} else {
-			currentDate := time.Now()
-
-			if currentDate.Before(dateStart) {
-				eventStatus = "upcoming"
-			} else {
-				if event.DateEnd != "" {
-					dateEnd, err := time.Parse("2006-01-02", event.DateEnd)
-					if err != nil {
-						eventStatus = "unknown"
-					} else if currentDate.Before(dateEnd) {
-						eventStatus = "ongoing"
-					} else {
-						eventStatus = "ended"
-					}
-				} else {
-					currentDateStr := currentDate.Format("2006-01-02")
-					currentDateOnly, _ := time.Parse("2006-01-02", currentDateStr)
-					dateStartOnly, _ := time.Parse("2006-01-02", event.DateStart)
-					
-					if currentDateOnly.After(dateStartOnly) {
-						eventStatus = "ended"
-					} else {
-						eventStatus = "ongoing"
-					}
-				}
-			}
-		}
+	query := shape.preload(database.DB, "").Where("visibility = ? AND publication_status = ?", "public", models.PublicationPublished)
+	events, _, ok := paginateEvents(c, query, "date", defaultPerPage)
+	if !ok {
+		return
+	}
 
-		var averageRating float64
-		if err := database.DB.Model(&models.Rating{}).
-			Where("event_id = ?", event.ID).
-			Select("COALESCE(AVG(rating), 0)").Scan(&averageRating).Error; err != nil {
-			averageRating = 0.0
-		}
-
-		averageRating = math.Round(averageRating*100) / 100
-
-		var uniqueRaters int64
-		if err := database.DB.Model(&models.Rating{}).
-			Where("event_id = ?", event.ID).
-			Select("COUNT(DISTINCT user_id)").Scan(&uniqueRaters).Error; err != nil {
-			uniqueRaters = 0
-		}
-
-		response = append(response, struct {
-			ID                uint    `json:"id"`
-			Name              string  `json:"name"`
-			Description       string  `json:"description"`
-			DateStart         string  `json:"date_start"`
-			DateEnd           string  `json:"date_end"`
-			Time              string  `json:"time"`
-			Location          string  `json:"location"`
-			Address           string  `json:"address"`
-			Capacity          int     `json:"capacity"`
-			RemainingCapacity int     `json:"remaining_capacity"`
-			Photo             string  `json:"photo"`
-			Price             string  `json:"price"`
-			Category          string  `json:"category"`
-			Status            string  `json:"status"`
-			Mode              string  `json:"mode"`
-			AverageRating     float64 `json:"average_rating"`
-			UniqueRaters      int64   `json:"unique_raters"`
-		}{
-			ID:                event.ID,
-			Name:              event.Name,
-			Description:       event.Description,
-			DateStart:         event.DateStart,
-			DateEnd:           event.DateEnd,
-			Time:              event.Time,
-			Location:          location.City,
-			Address:           event.Address,
-			Capacity:          event.Capacity,
-			RemainingCapacity: event.RemainingCapacity,
-			Photo:             event.Photo,
-			Price:             event.Price,
-			Category:          category.Name,
-			Status:            eventStatus,
-			Mode:              event.Mode,
-			AverageRating:     averageRating,
-			UniqueRaters:      uniqueRaters,
-		})
-	}
-
-	c.JSON(http.StatusOK, response)
+	result, err := serializeEvents(events, shape)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	body, err := shape.render(result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+		return
+	}
+	respondCached(c, body, modified, eventListCacheControl, "")
 }
 
 // get event per id
 func GetEventByID(c *gin.Context) {
 	id := c.Param("id")
 
+	shape, ok := parseResponseShape(c, serializers.EventDetailRelations, eventDetailInclude...)
+	if !ok {
+		return
+	}
+
 	var event models.Event
-	if err := database.DB.First(&event, id).Error; err != nil {
+	if err := shape.preload(database.DB, "").First(&event, id).Error; err != nil || !event.IsPublished() {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
 
-	var location models.Location
-	if event.LocationID != 0 {
-		if err := database.DB.First(&location, event.LocationID).Error; err != nil {
-			location.City = ""
-		}
-	} else {
-		location.City = "Online"
+	if _, ok := checkEventAccess(event, c.Query("access_code"), c.Query("invite_token")); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
 	}
 
-	var category models.Category
-	if err := database.DB.First(&category, event.CategoryID).Error; err != nil {
-		category.Name = ""
+	cacheControl := eventDetailCacheControl
+	if event.Visibility != "public" {
+		cacheControl = privateCacheControl
+	}
+	modified := eventLastModified(event.ID)
+	if notModifiedSince(c, modified, cacheControl) {
+		return
 	}
 
-	var eventStatus string
-	dateStart, err := time.Parse("2006-01-02", event.DateStart)
+	events := []models.Event{event}
+	listing, err := loadEventListing(events, shape)
 	if err != nil {
-		eventStatus = "unknown"
-	} else {
-		currentDate := time.Now()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return
+	}
+	event = events[0]
 
-		if currentDate.Before(dateStart) {
-			eventStatus = "upcoming"
-		} else {
-			if event.DateEnd != "" {
-				dateEnd, err := time.Parse("2006-01-02", event.DateEnd)
-				if err != nil {
-					eventStatus = "unknown"
-				} else if currentDate.Before(dateEnd) {
-					eventStatus = "ongoing"
-				} else {
-					eventStatus = "ended"
-				}
-			} else {
-				currentDateStr := currentDate.Format("2006-01-02")
-				currentDateOnly, _ := time.Parse("2006-01-02", currentDateStr)
-				dateStartOnly, _ := time.Parse("2006-01-02", event.DateStart)
-				
-				if currentDateOnly.After(dateStartOnly) {
-					eventStatus = "ended"
-				} else {
-					eventStatus = "ongoing"
-				}
-			}
+	var venue *models.Venue
+	if shape.needs("venue") {
+		venue = loadEventVenue(event)
+	}
+
+	body, err := shape.render(serializers.NewEventDetail(event, listing.meta(event), venue, time.Now()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+		return
+	}
+
+	// etag diawali id dan versi event supaya tetap bisa dipakai untuk If-Match saat update
+	respondCached(c, body, modified, cacheControl, fmt.Sprintf("%d-%d-", event.ID, event.Version))
+}
+
+// etag event berdasarkan versinya, dipakai untuk If-Match saat update
+func eventETag(event models.Event) string {
+	return fmt.Sprintf(`"%d-%d"`, event.ID, event.Version)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
 		}
 	}
+	return false
+}
 
-	var averageRating float64
-	if err := database.DB.Model(&models.Rating{}).
-		Where("event_id = ?", event.ID).
-		Select("COALESCE(AVG(rating), 0)").Scan(&averageRating).Error; err != nil {
-		averageRating = 0.0
-	}
-
-	averageRating = math.Round(averageRating*100) / 100
-
-	var uniqueRaters int64
-	if err := database.DB.Model(&models.Rating{}).
-		Where("event_id = ?", event.ID).
-		Select("COUNT(DISTINCT user_id)").Scan(&uniqueRaters).Error; err != nil {
-		uniqueRaters = 0
-	}
-
-	var eventLink string
-	if event.Mode == "online" {
-		eventLink = event.Link
-	}
-
-	var sessions []models.Session
-	if err := database.DB.Where("event_id = ?", event.ID).Find(&sessions).Error; err != nil {
-		sessions = []models.Session{}
-	}
-
-	response := struct {
-		ID                uint             `json:"id"`
-		Name              string           `json:"name"`
-		Description       string           `json:"description"`
-		DateStart         string           `json:"date_start"`
-		DateEnd           string           `json:"date_end"`
-		Time              string           `json:"time"`
-		Location          string           `json:"location"`
-		LocationID        uint             `json:"location_id"`
-		Address           string           `json:"address"`
-		Capacity          int              `json:"capacity"`
-		RemainingCapacity int              `json:"remaining_capacity"`
-		Photo             string           `json:"photo"`
-		Price             string           `json:"price"`
-		Category          string           `json:"category"`
-		CategoryID        uint             `json:"category_id"`
-		Benefits          string           `json:"benefits"`
-		Mode              string           `json:"mode"`
-		Link              string           `json:"link,omitempty"`
-		Status            string           `json:"status"`
-		AverageRating     float64          `json:"average_rating"`
-		UniqueRaters      int64            `json:"unique_raters"`
-		Sessions          []models.Session `json:"sessions"`
-	}{
-		ID:                event.ID,
-		Name:              event.Name,
-		Description:       event.Description,
-		DateStart:         event.DateStart,
-		DateEnd:           event.DateEnd,
-		Time:              event.Time,
-		Location:          location.City,
-		LocationID:        event.LocationID,
-		Address:           event.Address,
-		Capacity:          event.Capacity,
-		RemainingCapacity: event.RemainingCapacity,
-		Photo:             event.Photo,
-		Price:             event.Price,
-		Category:          category.Name,
-		CategoryID:        event.CategoryID,
-		Benefits:          event.Benefits,
-		Mode:              event.Mode,
-		Link:              eventLink,
-		Status:            eventStatus,
-		AverageRating:     averageRating,
-		UniqueRaters:      uniqueRaters,
-		Sessions:          sessions,
-	}
-
-	c.JSON(http.StatusOK, response)
+// etag dari detail event berbentuk "id-versi-hash", untuk If-Match cukup id dan versinya yang sama
+func eventETagMatches(header string, event models.Event) bool {
+	etag := eventETag(event)
+	prefix := strings.TrimSuffix(etag, `"`) + "-"
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || strings.HasPrefix(candidate, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
-// update event
+// update event, hanya field yang dikirim yang diubah (PUT dan PATCH)
 func UpdateEvent(c *gin.Context) {
-	var event models.Event
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
 
-	id := c.Param("id")
-	if err := database.DB.First(&event, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	// versi dari If-Match atau field version harus sama dengan versi sekarang
+	version := event.Version
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" && ifMatch != "*" && !eventETagMatches(ifMatch, event) {
+		c.Header("ETag", eventETag(event))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Event has been modified, reload and try again", "version": event.Version})
+		return
+	}
+	if value, ok := c.GetPostForm("version"); ok {
+		if clientVersion, err := strconv.Atoi(value); err != nil || clientVersion != version {
+			c.JSON(http.StatusConflict, gin.H{"error": "Event has been modified, reload and try again", "version": event.Version})
+			return
+		}
+	}
+
+	if err := ensureBaselineRevision(event.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record event history"})
 		return
 	}
 
@@ -461,183 +348,311 @@ func UpdateEvent(c *gin.Context) {
 		event.Photo = fmt.Sprintf("/uploads/%s", file.Filename)
 	}
 
-	event.Name = c.PostForm("name")
-	event.Description = c.PostForm("description")
-	event.DateStart = c.PostForm("datestart")
-	event.DateEnd = c.PostForm("dateend")
-	event.Time = c.PostForm("time")
-	event.Benefits = c.PostForm("benefits")
-	event.Mode = c.PostForm("mode")
-	event.Link = c.PostForm("link")
-	event.Price = c.PostForm("price")
-	if event.Price == "" {
-		event.Price = "Free"
+	// hanya field yang dikirim yang diubah
+	for field, target := range map[string]*string{
+		"name":        &event.Name,
+		"description": &event.Description,
+		"datestart":   &event.DateStart,
+		"dateend":     &event.DateEnd,
+		"time":        &event.Time,
+		"benefits":    &event.Benefits,
+		"mode":        &event.Mode,
+		"link":        &event.Link,
+		"address":     &event.Address,
+	} {
+		if value, ok := c.GetPostForm(field); ok {
+			*target = value
+		}
+	}
+	if price, ok := c.GetPostForm("price"); ok {
+		event.Price = price
+		if event.Price == "" {
+			event.Price = "Free"
+		}
+	}
+	if requiresApproval, err := strconv.ParseBool(c.PostForm("requires_approval")); err == nil {
+		event.RequiresApproval = requiresApproval
+	}
+	if visibility, ok := c.GetPostForm("visibility"); ok {
+		if !isValidVisibility(visibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+			return
+		}
+		event.Visibility = visibility
+	}
+	if accessCode, ok := c.GetPostForm("access_code"); ok {
+		event.AccessCode = accessCode
+	}
+	if transferPolicy, ok := c.GetPostForm("transfer_policy"); ok {
+		if !isValidTransferPolicy(transferPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer policy"})
+			return
+		}
+		event.TransferPolicy = transferPolicy
+	}
+	if transferDeadline, ok := c.GetPostForm("transfer_deadline"); ok {
+		if transferDeadline != "" {
+			if _, err := time.Parse("2006-01-02", transferDeadline); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer deadline format"})
+				return
+			}
+		}
+		event.TransferDeadline = transferDeadline
 	}
 
-	capacity, err := strconv.Atoi(c.PostForm("capacity"))
-	if err == nil {
-		 oldCapacity := event.Capacity
-		
-		event.Capacity = capacity
-		
-		if capacity < oldCapacity {
-			event.RemainingCapacity = capacity
+	// sisa kapasitas ikut bergeser sebesar perubahan kapasitas
+	if value, ok := c.GetPostForm("capacity"); ok {
+		capacity, err := strconv.Atoi(value)
+		if err != nil || capacity < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid capacity"})
+			return
+		}
+		event.RemainingCapacity += capacity - event.Capacity
+		if event.RemainingCapacity < 0 {
+			event.RemainingCapacity = 0
 		}
+		event.Capacity = capacity
 	}
 
-	remainingCapacity, err := strconv.Atoi(c.PostForm("remaining_capacity"))
-	if err == nil {
+	if value, ok := c.GetPostForm("remaining_capacity"); ok {
+		remainingCapacity, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid remaining capacity"})
+			return
+		}
+
 		if remainingCapacity > event.Capacity {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Remaining capacity tidak boleh melebihi capacity"})
 			return
 		}
-		
+
 		if remainingCapacity < 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Remaining capacity tidak boleh kurang dari 0"})
 			return
 		}
-		
-		event.RemainingCapacity = remainingCapacity
-	}
 
-	if err := database.DB.Model(&event).Updates(map[string]interface{}{
-		"capacity":           event.Capacity,
-		"remaining_capacity": event.RemainingCapacity,
-	}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update capacity"})
-		return
+		event.RemainingCapacity = remainingCapacity
 	}
 
-	locationID, err := strconv.Atoi(c.PostForm("location_id"))
-	if err == nil && locationID != 0 {
-		event.LocationID = uint(locationID)
-		var location models.Location
-		if err := database.DB.First(&location, event.LocationID).Error; err == nil {
+	// location_id kosong atau 0 berarti event online
+	if value, ok := c.GetPostForm("location_id"); ok {
+		locationID, err := strconv.Atoi(value)
+		if err == nil && locationID != 0 {
+			var location models.Location
+			if err := database.DB.First(&location, locationID).Error; err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+				return
+			}
+			event.LocationID = uint(locationID)
 			event.Location = location.City
-			event.Address = c.PostForm("address")
+			if event.Address == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Address is required when location is specified"})
+				return
+			}
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
-			return
+			event.Mode = "online"
+			event.LocationID = 0
+			event.Location = "Online"
+			event.Address = ""
 		}
-	} else {
-		event.Mode = "online"
-		event.LocationID = 0
-		event.Location = "Online"
-		event.Address = ""
 	}
 
-	categoryID, err := strconv.Atoi(c.PostForm("category_id"))
-	if err == nil {
+	oldCategoryID := event.CategoryID
+	if value, ok := c.GetPostForm("category_id"); ok {
+		categoryID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return
+		}
 		event.CategoryID = uint(categoryID)
 	}
 
+	if err := applyVenueFromForm(c, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if event.Mode == "online" && event.Link == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Link is required for online events"})
 		return
 	}
 
-	dateStart, err := time.Parse("2006-01-02", event.DateStart)
+	if scheduleChanged(c) {
+		if err := bindEventSchedule(c, &event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	event.Status = event.ComputeStatus(time.Now())
+
+	var currentSessions []models.Session
+	if err := database.DB.Where("event_id = ?", event.ID).Find(&currentSessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	existing := make(map[uint]models.Session, len(currentSessions))
+	for _, session := range currentSessions {
+		existing[session.ID] = session
+	}
+
+	sessions, err := sessionsFromForm(c, event, existing)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if event.DateEnd != "" {
-		dateEnd, err := time.Parse("2006-01-02", event.DateEnd)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+	tags, tagsPosted, err := tagsFromForm(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tags"})
+		return
+	}
+
+	_, categoryPosted := c.GetPostForm("category_id")
+	_, categoriesPosted := c.GetPostForm("category_ids")
+	var categories []models.Category
+	if categoryPosted || categoriesPosted {
+		var current []models.Category
+		database.DB.Model(&event).Association("Categories").Find(&current)
+		if categories, err = categoriesFromForm(c, event.CategoryID, current, oldCategoryID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		if dateEnd.Before(dateStart) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "End date cannot be before start date"})
+	}
+
+	var deleteSessionIDs []uint
+	for _, value := range strings.Split(c.PostForm("delete_sessions"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		sessionID, err := strconv.Atoi(value)
+		if _, ok := existing[uint(sessionID)]; err != nil || !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID in delete_sessions"})
 			return
 		}
+		deleteSessionIDs = append(deleteSessionIDs, uint(sessionID))
 	}
 
-	currentDate := time.Now()
-	if event.DateEnd == "" {
-		if currentDate.Before(dateStart) {
-			event.Status = "upcoming"
+	event.Version = version + 1
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	event.Sessions = nil
+	result := tx.Model(&event).Where("version = ?", version).Select("*").Updates(&event)
+	if result.Error != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "Event has been modified, reload and try again"})
+		return
+	}
+
+	for i := range sessions {
+		var err error
+		if sessions[i].ID == 0 {
+			err = tx.Create(&sessions[i]).Error
 		} else {
-			event.Status = "ongoing"
+			err = tx.Save(&sessions[i]).Error
 		}
-	} else {
-		dateEnd, err := time.Parse("2006-01-02", event.DateEnd)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sessions", "details": err.Error()})
 			return
 		}
-		if dateEnd.Before(dateStart) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "End date cannot be before start date"})
+	}
+
+	if len(deleteSessionIDs) > 0 {
+		if err := tx.Where("event_id = ? AND id IN ?", event.ID, deleteSessionIDs).Delete(&models.Session{}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete sessions", "details": err.Error()})
 			return
 		}
-		if currentDate.Before(dateStart) {
-			event.Status = "upcoming"
-		} else if currentDate.After(dateStart) && currentDate.Before(dateEnd) {
-			event.Status = "ongoing"
-		} else {
-			event.Status = "ended"
-		}
 	}
 
-	if err := database.DB.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event", "details": err.Error()})
-		return
+	// ruangan dari venue lama dilepas kalau venue event berganti
+	if _, ok := c.GetPostForm("venue_id"); ok {
+		rooms := tx.Model(&models.VenueRoom{}).Select("id")
+		if event.VenueID != nil {
+			rooms = rooms.Where("venue_id <> ?", *event.VenueID)
+		}
+		if err := tx.Model(&models.Session{}).Where("event_id = ? AND venue_room_id IN (?)", event.ID, rooms).Update("venue_room_id", nil).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sessions", "details": err.Error()})
+			return
+		}
 	}
 
-	var sessions []models.Session
-	database.DB.Where("event_id = ?", event.ID).Delete(&sessions)
-
-	for i := 0; ; i++ {
-		date := c.PostForm(fmt.Sprintf("sessions[%d][date]", i))
-		sessionTime := c.PostForm(fmt.Sprintf("sessions[%d][time]", i))
-		speaker := c.PostForm(fmt.Sprintf("sessions[%d][speaker]", i))
-		location := c.PostForm(fmt.Sprintf("sessions[%d][location]", i))
-
-		if date == "" {
-			break
+	if tagsPosted {
+		if err := tx.Model(&event).Association("Tags").Replace(tags); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tags", "details": err.Error()})
+			return
 		}
+	}
 
-		_, err := time.Parse("2006-01-02", date)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Format tanggal tidak valid untuk sesi %d", i)})
+	if categoryPosted || categoriesPosted {
+		if err := tx.Model(&event).Association("Categories").Replace(categories); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update categories", "details": err.Error()})
 			return
 		}
+	}
+
+	tx.Commit()
 
-		sessions = append(sessions, models.Session{
-			EventID:  event.ID,
-			Date:     date,
-			Time:     sessionTime,
-			Speaker:  speaker,
-			Location: location,
-		})
+	if err := database.DB.Where("event_id = ?", event.ID).Order("starts_at, id").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
 	}
 
-	if len(sessions) > 0 {
-		if err := database.DB.Create(&sessions).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sessions", "details": err.Error()})
-			return
-		}
+	database.DB.Model(&event).Association("Tags").Find(&event.Tags)
+	database.DB.Model(&event).Association("Categories").Find(&event.Categories)
+
+	scheduler.Wake()
+	recordEventChange(c, event.ID, "")
+
+	response, err := managedEventResponse(event, sessions)
+	if err != nil {
+		log.Printf("Gagal memuat data pendamping event %d: %v", event.ID, err)
 	}
 
+	c.Header("ETag", eventETag(event))
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Event and sessions updated successfully",
-		"event":    event,
-		"sessions": sessions,
+		"event":    response,
+		"sessions": serializers.NewSessions(sessions),
 	})
 }
 
 // delete event
 func DeleteEvent(c *gin.Context) {
-	id := c.Param("id")
-
-	var event models.Event
-	if err := database.DB.First(&event, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	event, _, ok := findManagedEvent(c)
+	if !ok {
 		return
 	}
 
-	if err := database.DB.Delete(&event).Error; err != nil {
+	// event yang belum selesai dan masih punya pendaftar harus dibatalkan dulu
+	status := event.ComputeStatus(time.Now())
+	if status != models.StatusEnded && status != models.StatusCancelled {
+		var active int64
+		database.DB.Model(&models.Registration{}).Where("event_id = ? AND status IN ?", event.ID, []string{"approved", "pending"}).Count(&active)
+		if active > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Event still has active registrations, cancel the event first", "registrations": active})
+			return
+		}
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return softDeleteEvent(tx, event.ID, time.Now())
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
 		return
 	}
@@ -661,21 +676,26 @@ func GetEventRegistrants(c *gin.Context) {
 		return
 	}
 
+	shape, ok := parseResponseShape(c, nil)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Where("event_id = ?", id)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
 	var userEvents []models.Registration
-	if err := database.DB.Where("event_id = ?", id).Find(&userEvents).Error; err != nil {
+	if err := query.Find(&userEvents).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registrants"})
 		return
 	}
 
-	var registrants []gin.H
-	for _, ue := range userEvents {
-		registrants = append(registrants, gin.H{
-			"username": ue.Username,
-			"name":     ue.Name,
-			"email":    ue.Email,
-			"phone":    ue.PhoneNumber,
-			"job":      ue.Job,
-		})
+	registrants, err := shape.render(serializers.NewRegistrants(userEvents))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -695,88 +715,43 @@ func GetRegisteredEvents(c *gin.Context) {
 
     loggedInUser := user.(models.User)
 
+    shape, ok := parseResponseShape(c, serializers.RegisteredEventRelations, registeredEventInclude...)
+    if !ok {
+        return
+    }
+
     var registeredEvents []models.Registration
-    if err := database.DB.Preload("Event").Where("user_id = ?", loggedInUser.ID).Find(&registeredEvents).Error; err != nil {
+    if err := shape.preload(database.DB.Preload("Event"), "Event.").Where("user_id = ? AND group_id IS NULL", loggedInUser.ID).Find(&registeredEvents).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registered events"})
         return
     }
 
-    currentDate := time.Now()
-    var events []gin.H
+    registered := make([]models.Event, 0, len(registeredEvents))
     for _, ue := range registeredEvents {
-        var averageRating float64
-        if err := database.DB.Model(&models.Rating{}).
-            Where("event_id = ?", ue.Event.ID).
-            Select("COALESCE(AVG(rating), 0)").Scan(&averageRating).Error; err != nil {
-            averageRating = 0.0
-        }
-        averageRating = math.Round(averageRating*100) / 100
-
-        var uniqueRaters int64
-        if err := database.DB.Model(&models.Rating{}).
-            Where("event_id = ?", ue.Event.ID).
-            Select("COUNT(DISTINCT user_id)").Scan(&uniqueRaters).Error; err != nil {
-            uniqueRaters = 0
-        }
-
-        dateStart, err := time.Parse("2006-01-02", ue.Event.DateStart)
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
-            return
-        }
-
-        var status string
-        if ue.Event.DateEnd == "" {
-            if currentDate.Before(dateStart) {
-                status = "akan datang"
-            } else {
-                status = "sedang berjalan"
-            }
-        } else {
-            dateEnd, err := time.Parse("2006-01-02", ue.Event.DateEnd)
-            if err != nil {
-                c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-                return
-            }
-
-            if dateEnd.Before(dateStart) {
-                c.JSON(http.StatusBadRequest, gin.H{"error": "End date cannot be before start date"})
-                return
-            }
-
-            if currentDate.Before(dateStart) {
-                status = "akan datang"
-            } else if currentDate.After(dateStart) && currentDate.Before(dateEnd) {
-                status = "sedang berjalan"
-            } else {
-                status = "selesai"
-            }
-        }
-
-        events = append(events, gin.H{
-            "id":           ue.Event.ID,
-            "name":         ue.Event.Name,
-            "description":  ue.Event.Description,
-            "date":         ue.Event.DateStart,
-            "time":         ue.Event.Time,
-            "location":     ue.Event.Location,
-            "address":      ue.Event.Address,
-            "capacity":     ue.Event.Capacity,
-            "photo":        ue.Event.Photo,
-            "price":        ue.Event.Price,
-            "name_reg":     ue.Name,
-            "email":        ue.Email,
-            "phone":        ue.PhoneNumber,
-            "job":          ue.Job,
-            "status":       status,   
-            "rating":       averageRating,
-            "uniqueraters": uniqueRaters,
-        })
+        registered = append(registered, ue.Event)
+    }
+    listing, err := loadEventListing(registered, shape)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registered events"})
+        return
+    }
+
+    currentDate := time.Now()
+    events := make([]serializers.RegisteredEvent, 0, len(registeredEvents))
+    for i, ue := range registeredEvents {
+        ue.Event = registered[i]
+        events = append(events, serializers.NewRegisteredEvent(ue, listing.meta(ue.Event), currentDate))
+    }
+
+    body, err := shape.render(events)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+        return
     }
 
     c.JSON(http.StatusOK, gin.H{
         "username":          loggedInUser.Username,
-        "registered_events": events,
+        "registered_events": body,
     })
 }
 
@@ -797,8 +772,8 @@ func CheckRegistration(c *gin.Context) {
 	}
 
 	var registration models.Registration
-	if err := database.DB.Where("user_id = ? AND event_id = ?", loggedInUser.ID, eventID).First(&registration).Error; err == nil {
-		c.JSON(http.StatusOK, gin.H{"isRegistered": true})
+	if err := database.DB.Where("user_id = ? AND event_id = ? AND group_id IS NULL", loggedInUser.ID, eventID).First(&registration).Error; err == nil {
+		c.JSON(http.StatusOK, gin.H{"isRegistered": true, "status": registration.Status})
 		return
 	}
 
@@ -823,96 +798,41 @@ func GetUnregisteredEvents(c *gin.Context) {
 		return
 	}
 
-	var eventsToDisplay []models.Event
-
-	if len(registeredEventIDs) == 0 {
-		if err := database.DB.Where("status IN ?", []string{"upcoming", "ongoing"}).
-			Limit(6).Find(&eventsToDisplay).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
-			return
-		}
-	} else {
-		if err := database.DB.Where("id NOT IN ? AND status IN ?", registeredEventIDs, []string{"upcoming", "ongoing"}).
-			Limit(6).Find(&eventsToDisplay).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unregistered events"})
-			return
-		}
+	shape, ok := parseResponseShape(c, serializers.EventRelations, eventListInclude...)
+	if !ok {
+		return
 	}
 
-	var events []gin.H
-	currentDate := time.Now()
-
-	for _, event := range eventsToDisplay {
-		if event.DateEnd == "" {
-			dateStart, err := time.Parse("2006-01-02", event.DateStart)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
-				return
-			}
-			if currentDate.Before(dateStart) {
-				event.Status = "upcoming"
-			} else {
-				event.Status = "ongoing"
-			}
-		} else {
-			dateStart, err := time.Parse("2006-01-02", event.DateStart)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
-				return
-			}
-
-			dateEnd, err := time.Parse("2006-01-02", event.DateEnd)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-				return
-			}
-
-			if currentDate.Before(dateStart) {
-				event.Status = "upcoming"
-			} else if currentDate.After(dateStart) && currentDate.Before(dateEnd) {
-				event.Status = "ongoing"
-			} else {
-				event.Status = "ended"
-			}
-		}
-
-		if event.Status == "upcoming" || event.Status == "ongoing" {
-			var averageRating float64
-			if err := database.DB.Model(&models.Rating{}).
-				Where("event_id = ?", event.ID).
-				Select("COALESCE(AVG(rating), 0)").Scan(&averageRating).Error; err != nil {
-				averageRating = 0.0
-			}
-			averageRating = math.Round(averageRating*100) / 100
-
-			var uniqueRaters int64
-			if err := database.DB.Model(&models.Rating{}).
-				Where("event_id = ?", event.ID).
-				Select("COUNT(DISTINCT user_id)").Scan(&uniqueRaters).Error; err != nil {
-				uniqueRaters = 0
-			}
+	// yang sudah lewat, dibatalkan atau ditunda tidak ditampilkan. disaring di SQL sebelum
+	// paginasi (sama dengan ComputeStatus: event tanpa ends_at selesai sehari setelah mulai)
+	// supaya setiap halaman penuh dan total di pagination benar
+	query := shape.preload(database.DB, "").
+		Where("visibility = ? AND publication_status = ?", "public", models.PublicationPublished).
+		Where("status NOT IN ?", []string{models.StatusCancelled, models.StatusPostponed}).
+		Where("COALESCE(ends_at, starts_at + INTERVAL '1 day') > ?", time.Now())
+	if len(registeredEventIDs) > 0 {
+		query = query.Where("id NOT IN ?", registeredEventIDs)
+	}
+	eventsToDisplay, page, ok := paginateEvents(c, query, "date", 6)
+	if !ok {
+		return
+	}
 
-			events = append(events, gin.H{
-				"id":             event.ID,
-				"name":           event.Name,
-				"description":    event.Description,
-				"date":           event.DateStart,
-				"time":           event.Time,
-				"location":       event.Location,
-				"address":        event.Address,
-				"capacity":       event.Capacity,
-				"photo":          event.Photo,
-				"price":          event.Price,
-				"status":         event.Status,
-				"average_rating": averageRating,
-				"unique_raters":  uniqueRaters,
-			})
-		}
+	result, err := serializeEvents(eventsToDisplay, shape)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	events, err := shape.render(result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"username":            loggedInUser.Username,
 		"unregistered_events": events,
+		"pagination":          page.meta(),
 	})
 }
 
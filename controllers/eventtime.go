@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"backend-event/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var indonesianMonths = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+func formatIndonesianDate(t time.Time) string {
+	return fmt.Sprintf("%02d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// tanggal event untuk email, pakai zona waktu event (contoh: 02 Januari 2025, 09:00 - 12:00 WIB)
+func formatEventDate(event models.Event) string {
+	start, end, err := event.Period()
+	if err != nil {
+		return event.DateStart
+	}
+
+	lastDay := end
+	if end.Hour() == 0 && end.Minute() == 0 && end.After(start) {
+		lastDay = end.AddDate(0, 0, -1)
+	}
+
+	formatted := formatIndonesianDate(start)
+	if lastDay.Format(models.DateLayout) != start.Format(models.DateLayout) {
+		formatted += " - " + formatIndonesianDate(lastDay)
+	}
+
+	if start.Hour() != 0 || start.Minute() != 0 {
+		formatted += ", " + start.Format(models.ClockLayout)
+		if end.Hour() != 0 || end.Minute() != 0 {
+			formatted += " - " + end.Format(models.ClockLayout)
+		}
+		formatted += " " + start.Format("MST")
+	}
+	return formatted
+}
+
+// jadwal hanya dibaca ulang kalau salah satu field jadwal dikirim
+func scheduleChanged(c *gin.Context) bool {
+	for _, field := range []string{"datestart", "dateend", "time", "starts_at", "ends_at", "timezone"} {
+		if _, ok := c.GetPostForm(field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// baca jadwal event dari form: starts_at/ends_at (RFC3339 atau waktu lokal)
+// atau field lama datestart/dateend/time, dalam zona waktu event
+func bindEventSchedule(c *gin.Context, event *models.Event) error {
+	if timezone := c.PostForm("timezone"); timezone != "" {
+		if _, err := models.LoadTimezone(timezone); err != nil {
+			return errors.New("Invalid timezone")
+		}
+		event.Timezone = timezone
+	}
+	if event.Timezone == "" {
+		event.Timezone = models.DefaultTimezone
+	}
+
+	startsAt := c.PostForm("starts_at")
+	if startsAt == "" {
+		return event.ScheduleFromLegacy()
+	}
+
+	loc := event.TimeLocation()
+	start, err := models.ParseTimestamp(startsAt, loc)
+	if err != nil {
+		return models.ErrInvalidStartDate
+	}
+
+	end := models.AtClock(start, 0).AddDate(0, 0, 1)
+	if endsAt := c.PostForm("ends_at"); endsAt != "" {
+		end, err = models.ParseTimestamp(endsAt, loc)
+		if err != nil {
+			return models.ErrInvalidEndDate
+		}
+	}
+
+	return event.SetSchedule(start, end)
+}
+
+// baca sesi dari form sessions[i][...], berhenti di index pertama yang kosong.
+// sessions[i][id] mengubah sesi yang ada di existing, hanya field yang dikirim yang berubah
+func sessionsFromForm(c *gin.Context, event models.Event, existing map[uint]models.Session) ([]models.Session, error) {
+	var sessions []models.Session
+	for i := 0; ; i++ {
+		field := func(name string) (string, bool) {
+			return c.GetPostForm(fmt.Sprintf("sessions[%d][%s]", i, name))
+		}
+
+		id, _ := field("id")
+		date, hasDate := field("date")
+		startsAt, _ := field("starts_at")
+
+		if id == "" && date == "" && startsAt == "" {
+			break
+		}
+
+		var session models.Session
+		if id != "" {
+			sessionID, err := strconv.Atoi(id)
+			current, ok := existing[uint(sessionID)]
+			if err != nil || !ok {
+				return nil, fmt.Errorf("Sesi %d tidak ditemukan", i)
+			}
+			session = current
+		} else {
+			session.EventID = event.ID
+			session.Timezone = event.Timezone
+		}
+
+		if hasDate {
+			session.Date = date
+		}
+		if value, ok := field("time"); ok {
+			session.Time = value
+		}
+		if value, ok := field("timezone"); ok && value != "" {
+			session.Timezone = value
+		}
+		if value, ok := field("speaker"); ok {
+			session.Speaker = value
+		}
+		if value, ok := field("location"); ok {
+			session.Location = value
+		}
+		if value, ok := field("room_id"); ok {
+			room, err := findVenueRoom(event, value)
+			if err != nil {
+				return nil, fmt.Errorf("Ruangan tidak valid untuk sesi %d", i)
+			}
+			session.VenueRoomID = nil
+			if room != nil {
+				session.VenueRoomID = &room.ID
+				if session.Location == "" {
+					session.Location = room.Name
+				}
+			}
+		}
+		if session.Timezone == "" {
+			session.Timezone = event.Timezone
+		}
+		if _, err := models.LoadTimezone(session.Timezone); err != nil {
+			return nil, fmt.Errorf("Zona waktu tidak valid untuk sesi %d", i)
+		}
+
+		_, hasTime := field("time")
+		_, hasTimezone := field("timezone")
+
+		if startsAt != "" {
+			loc := session.TimeLocation()
+			start, err := models.ParseTimestamp(startsAt, loc)
+			if err != nil {
+				return nil, fmt.Errorf("Format tanggal tidak valid untuk sesi %d", i)
+			}
+
+			var end time.Time
+			if endsAt, _ := field("ends_at"); endsAt != "" {
+				if end, err = models.ParseTimestamp(endsAt, loc); err != nil {
+					return nil, fmt.Errorf("Format tanggal tidak valid untuk sesi %d", i)
+				}
+			}
+
+			if err := session.SetSchedule(start, end); err != nil {
+				return nil, fmt.Errorf("Jam selesai sesi %d tidak boleh sebelum jam mulai", i)
+			}
+		} else if session.ID == 0 || hasDate || hasTime || hasTimezone {
+			if err := session.ScheduleFromLegacy(); err != nil {
+				return nil, fmt.Errorf("Format tanggal tidak valid untuk sesi %d", i)
+			}
+		}
+
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// salin sesi ke event lain dengan jadwal digeser sejauh shift
+func copySessions(sessions []models.Session, eventID uint, shift time.Duration) ([]models.Session, error) {
+	copies := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		session.ID = 0
+		session.EventID = eventID
+		if session.StartsAt == nil {
+			if err := session.ScheduleFromLegacy(); err != nil {
+				continue
+			}
+		}
+
+		var end time.Time
+		if session.EndsAt != nil {
+			end = session.EndsAt.Add(shift)
+		}
+		if err := session.SetSchedule(session.StartsAt.Add(shift), end); err != nil {
+			return nil, err
+		}
+		copies = append(copies, session)
+	}
+	return copies, nil
+}
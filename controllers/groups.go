@@ -0,0 +1,320 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/serializers"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type attendeeInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Job   string `json:"job"`
+}
+
+func groupResponse(group models.RegistrationGroup, registrations []models.Registration) gin.H {
+	return gin.H{
+		"id":             group.ID,
+		"event_id":       group.EventID,
+		"quantity":       group.Quantity,
+		"contact_name":   group.ContactName,
+		"contact_email":  group.ContactEmail,
+		"contact_phone":  group.ContactPhone,
+		"payment_method": group.PaymentMethod,
+		"payment_status": group.PaymentStatus,
+		"created_at":     group.CreatedAt,
+		"attendees":      serializers.NewAttendees(registrations),
+	}
+}
+
+// daftar beberapa kursi sekaligus dengan satu pembayaran
+func RegisterGroup(c *gin.Context) {
+	eventID := c.Param("event_id")
+	var event models.Event
+
+	if err := database.DB.First(&event, eventID).Error; err != nil || !event.IsPublished() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event tidak ditemukan"})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terotorisasi"})
+		return
+	}
+
+	loggedInUser, ok := user.(models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Data user tidak valid"})
+		return
+	}
+
+	var input struct {
+		Quantity      int             `json:"quantity"`
+		ContactName   string          `json:"contact_name"`
+		ContactEmail  string          `json:"contact_email"`
+		ContactPhone  string          `json:"contact_phone"`
+		PaymentMethod string          `json:"payment_method"`
+		AccessCode    string          `json:"access_code"`
+		InviteToken   string          `json:"invite_token"`
+		Attendees     []attendeeInput `json:"attendees"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		return
+	}
+
+	if input.Quantity < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah kursi minimal 1"})
+		return
+	}
+
+	if len(input.Attendees) > input.Quantity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah peserta melebihi jumlah kursi"})
+		return
+	}
+
+	if input.ContactEmail == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email kontak diperlukan"})
+		return
+	}
+
+	invitation, ok := checkEventAccess(event, input.AccessCode, input.InviteToken)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Event ini hanya untuk undangan, kode akses atau undangan tidak valid"})
+		return
+	}
+	if invitation != nil && invitation.Status == "accepted" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Undangan sudah digunakan"})
+		return
+	}
+
+	if strings.ToLower(event.Price) != "free" && input.PaymentMethod == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Metode pembayaran diperlukan untuk event berbayar"})
+		return
+	}
+
+	status := "approved"
+	if event.RequiresApproval {
+		status = "pending"
+	}
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if status == "approved" {
+		if err := reserveSeats(tx, event.ID, input.Quantity); err != nil {
+			tx.Rollback()
+			if errors.Is(err, errEventFull) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Sisa kapasitas event tidak mencukupi"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kapasitas event"})
+			return
+		}
+	}
+
+	group := models.RegistrationGroup{
+		EventID:       event.ID,
+		UserID:        loggedInUser.ID,
+		Quantity:      input.Quantity,
+		ContactName:   input.ContactName,
+		ContactEmail:  input.ContactEmail,
+		ContactPhone:  input.ContactPhone,
+		PaymentMethod: input.PaymentMethod,
+	}
+
+	if err := tx.Create(&group).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mendaftar event"})
+		return
+	}
+
+	registrations := make([]models.Registration, input.Quantity)
+	for i := range registrations {
+		ticketCode, err := generateTicketCode()
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kode tiket"})
+			return
+		}
+
+		registrations[i] = models.Registration{
+			UserID:        &loggedInUser.ID,
+			EventID:       event.ID,
+			Username:      loggedInUser.Username,
+			PaymentMethod: input.PaymentMethod,
+			Status:        status,
+			GroupID:       &group.ID,
+			TicketCode:    ticketCode,
+		}
+		if i < len(input.Attendees) {
+			registrations[i].Name = input.Attendees[i].Name
+			registrations[i].Email = input.Attendees[i].Email
+			registrations[i].PhoneNumber = input.Attendees[i].Phone
+			registrations[i].Job = input.Attendees[i].Job
+		}
+	}
+
+	if err := tx.Create(&registrations).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mendaftar event"})
+		return
+	}
+
+	if invitation != nil {
+		if err := tx.Model(invitation).Update("status", "accepted").Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui status undangan"})
+			return
+		}
+	}
+
+	if status == "approved" && strings.ToLower(event.Price) != "free" && input.PaymentMethod != "" {
+		if err := sendPaymentConfirmationEmail(group.ContactEmail, group.ContactName, event.Name, event.Description, formatEventDate(event), event.Location, input.PaymentMethod); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email konfirmasi pembayaran"})
+			return
+		}
+	}
+
+	for _, registration := range registrations {
+		if registration.Email == "" {
+			continue
+		}
+		if err := sendAttendeeEmail(registration, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email ke peserta " + registration.Email})
+			return
+		}
+	}
+
+	tx.Commit()
+
+	if err := UpdatePopularityScore(event.ID); err != nil {
+		log.Printf("Gagal memperbarui popularity score: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Berhasil mendaftarkan grup untuk event",
+		"status":  status,
+		"group":   groupResponse(group, registrations),
+	})
+}
+
+// kirim tiket ke peserta grup sesuai status pendaftarannya
+func sendAttendeeEmail(registration models.Registration, event models.Event) error {
+	if registration.Status == "pending" {
+		return sendRegistrationStatusEmail(registration.Email, registration.Name, event.Name, registration.Status, "")
+	}
+	return sendEmail(registration.Email, registration.Name, registration.PhoneNumber, registration.Job, event.Name, event.Location, formatEventDate(event), event.Description, event.Mode, event.Link, event.Address, registration.TicketCode)
+}
+
+// daftar grup milik user
+func GetMyGroups(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	loggedInUser := user.(models.User)
+
+	var groups []models.RegistrationGroup
+	if err := database.DB.Where("user_id = ?", loggedInUser.ID).Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+func findOwnedGroup(c *gin.Context) (models.RegistrationGroup, bool) {
+	var group models.RegistrationGroup
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return group, false
+	}
+
+	loggedInUser := user.(models.User)
+
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), loggedInUser.ID).First(&group).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grup tidak ditemukan"})
+		return group, false
+	}
+
+	return group, true
+}
+
+func GetGroupByID(c *gin.Context) {
+	group, ok := findOwnedGroup(c)
+	if !ok {
+		return
+	}
+
+	var registrations []models.Registration
+	if err := database.DB.Where("group_id = ?", group.ID).Order("id").Find(&registrations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groupResponse(group, registrations))
+}
+
+// isi atau ganti data peserta untuk satu kursi di grup
+func UpdateGroupAttendee(c *gin.Context) {
+	group, ok := findOwnedGroup(c)
+	if !ok {
+		return
+	}
+
+	var registration models.Registration
+	if err := database.DB.Where("id = ? AND group_id = ?", c.Param("registration_id"), group.ID).First(&registration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Peserta tidak ditemukan"})
+		return
+	}
+
+	var input attendeeInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == "" || input.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama dan email peserta diperlukan"})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.First(&event, group.EventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event tidak ditemukan"})
+		return
+	}
+
+	registration.Name = input.Name
+	registration.Email = input.Email
+	registration.PhoneNumber = input.Phone
+	registration.Job = input.Job
+
+	if err := database.DB.Save(&registration).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui data peserta"})
+		return
+	}
+
+	if registration.Status != "rejected" {
+		if err := sendAttendeeEmail(registration, event); err != nil {
+			log.Printf("Gagal mengirim email ke peserta %s: %v", registration.Email, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Data peserta berhasil diperbarui"})
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	gomail "gopkg.in/mail.v2"
+)
+
+func isValidVisibility(visibility string) bool {
+	return visibility == "public" || visibility == "unlisted" || visibility == "private"
+}
+
+func generateToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// cek akses event private lewat kode akses atau token undangan
+func checkEventAccess(event models.Event, accessCode, inviteToken string) (*models.Invitation, bool) {
+	if event.Visibility != "private" {
+		return nil, true
+	}
+
+	if inviteToken != "" {
+		var invitation models.Invitation
+		if err := database.DB.Where("event_id = ? AND token = ? AND status <> ?", event.ID, inviteToken, "revoked").First(&invitation).Error; err == nil {
+			return &invitation, true
+		}
+	}
+
+	if event.AccessCode != "" && accessCode == event.AccessCode {
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// kirim undangan ke daftar email
+func CreateInvitations(c *gin.Context) {
+	event, _, ok := findManagedEventParam(c, "event_id")
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Invitees []struct {
+			Email string `json:"email"`
+			Name  string `json:"name"`
+		} `json:"invitees"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || len(input.Invitees) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var invitations []models.Invitation
+	var failed []gin.H
+	for _, invitee := range input.Invitees {
+		email := strings.TrimSpace(invitee.Email)
+		if email == "" {
+			continue
+		}
+
+		token, err := generateToken(16)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation token"})
+			return
+		}
+
+		invitation := models.Invitation{
+			EventID: event.ID,
+			Email:   email,
+			Name:    invitee.Name,
+			Token:   token,
+			Status:  "sent",
+		}
+
+		if err := database.DB.Create(&invitation).Error; err != nil {
+			failed = append(failed, gin.H{"email": email, "error": "Failed to create invitation"})
+			continue
+		}
+
+		if err := sendInvitationEmail(invitation, event); err != nil {
+			failed = append(failed, gin.H{"email": email, "error": "Failed to send invitation email"})
+			continue
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Invitations sent",
+		"invitations": invitations,
+		"failed":      failed,
+	})
+}
+
+func GetInvitations(c *gin.Context) {
+	event, _, ok := findManagedEventParam(c, "event_id")
+	if !ok {
+		return
+	}
+
+	var invitations []models.Invitation
+	if err := database.DB.Where("event_id = ?", event.ID).Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// cabut undangan, token tidak bisa dipakai lagi
+func RevokeInvitation(c *gin.Context) {
+	event, _, ok := findManagedEventParam(c, "event_id")
+	if !ok {
+		return
+	}
+
+	var invitation models.Invitation
+	if err := database.DB.Where("id = ? AND event_id = ?", c.Param("invitation_id"), event.ID).First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	if err := database.DB.Model(&invitation).Update("status", "revoked").Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+func sendInvitationEmail(invitation models.Invitation, event models.Event) error {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	link := fmt.Sprintf("%s/events/%d?invite_token=%s", strings.TrimRight(frontendURL, "/"), event.ID, invitation.Token)
+
+	name := invitation.Name
+	if name == "" {
+		name = invitation.Email
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", os.Getenv("SMTP_USER"))
+	m.SetHeader("To", invitation.Email)
+	m.SetHeader("Subject", "Undangan Event - "+event.Name)
+
+	body := fmt.Sprintf(`
+		<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto;">
+			<h2 style="color: #333;">Halo, %s!</h2>
+			<p>Anda diundang untuk menghadiri event:</p>
+
+			<div style="background-color: #f8f9fa; padding: 20px; border-radius: 5px; margin: 15px 0;">
+				<h3 style="color: #007bff; margin-top: 0;">%s</h3>
+				<p style="color: #666;"><strong>Deskripsi:</strong> %s</p>
+				<p style="color: #666;"><strong>Tanggal:</strong> %s</p>
+				<p style="color: #666;"><strong>Lokasi:</strong> %s</p>
+			</div>
+
+			<p>
+				<a href="%s" style="color: #007bff; text-decoration: none;">Klik di sini untuk melihat event dan mendaftar</a>
+			</p>
+			<p style="color: #856404; font-size: 14px;">
+				<strong>Catatan:</strong> Link undangan ini bersifat pribadi dan hanya dapat digunakan satu kali untuk mendaftar
+			</p>
+
+			<div style="margin-top: 20px; padding-top: 20px; border-top: 1px solid #eee;">
+				<p style="color: #666; font-size: 14px;">
+					Jika Anda memiliki pertanyaan, silakan hubungi tim support kami di:<br>
+					Email: anjarriho081@gmail.com<br>
+					WhatsApp: +62 890 3333 4444
+				</p>
+			</div>
+		</div>
+	`, name, event.Name, event.Description, formatEventDate(event), event.Location, link)
+
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer("smtp.gmail.com", 587, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"))
+	if err := d.DialAndSend(m); err != nil {
+		return err
+	}
+	return nil
+}
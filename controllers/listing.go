@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// harga event disimpan sebagai teks ("Free", "50000"), angka diambil dari digitnya
+const eventPriceSQL = `COALESCE(NULLIF(regexp_replace(events.price, '[^0-9]', '', 'g'), '')::numeric, 0)`
+
+const eventRatingSQL = `COALESCE((SELECT AVG(ratings.rating) FROM ratings
+	WHERE ratings.event_id = events.id AND ratings.deleted_at IS NULL), 0)`
+
+type eventSort struct {
+	expr string
+	cast string
+	desc bool
+}
+
+// pilihan ?sort=, awalan "-" membalik urutan (misalnya -price)
+var eventSorts = map[string]eventSort{
+	"date":       {expr: "COALESCE(events.starts_at, 'infinity'::timestamptz)", cast: "timestamptz"},
+	"popularity": {expr: "events.popularity_score", cast: "double precision", desc: true},
+	"rating":     {expr: eventRatingSQL, cast: "numeric", desc: true},
+	"price":      {expr: eventPriceSQL, cast: "numeric"},
+	"newest":     {expr: "events.id", cast: "bigint", desc: true},
+}
+
+type eventPage struct {
+	Page       int
+	PerPage    int
+	Total      int64
+	HasMore    bool
+	NextCursor string
+	cursorMode bool
+}
+
+// metadata halaman untuk response yang berbentuk objek
+func (p eventPage) meta() gin.H {
+	meta := gin.H{"total": p.Total, "per_page": p.PerPage, "has_more": p.HasMore}
+	if p.cursorMode {
+		meta["next_cursor"] = p.NextCursor
+	} else {
+		meta["page"] = p.Page
+		meta["total_pages"] = (p.Total + int64(p.PerPage) - 1) / int64(p.PerPage)
+	}
+	return meta
+}
+
+// ?page= dan ?per_page= (maksimal 100)
+func pageFromQuery(c *gin.Context, defaultPerPage int) eventPage {
+	page := eventPage{Page: 1, PerPage: defaultPerPage}
+	if perPage, err := strconv.Atoi(c.Query("per_page")); err == nil && perPage > 0 {
+		page.PerPage = int(math.Min(float64(perPage), maxPerPage))
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page.Page = p
+	}
+	return page
+}
+
+func parseSort(value, fallback string) (eventSort, error) {
+	if value == "" {
+		value = fallback
+	}
+	reverse := strings.HasPrefix(value, "-")
+	sort, ok := eventSorts[strings.TrimPrefix(value, "-")]
+	if !ok {
+		return sort, errors.New("Invalid sort, must be date, popularity, rating, price or newest")
+	}
+	if reverse {
+		sort.desc = !sort.desc
+	}
+	return sort, nil
+}
+
+// tanggal filter boleh RFC3339 atau waktu lokal WIB. "to" berupa tanggal saja mencakup seluruh hari itu
+func parseFilterTime(value string, endOfDay bool) (time.Time, error) {
+	loc, _ := models.LoadTimezone(models.DefaultTimezone)
+	t, err := models.ParseTimestamp(value, loc)
+	if err == nil && endOfDay && len(strings.TrimSpace(value)) == len(models.DateLayout) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, err
+}
+
+// filter umum daftar event: kategori/tag, wilayah, location_id, mode, status, rentang tanggal,
+// rentang harga, gratis dan sisa kursi
+func applyEventFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	query = filterEventsByTaxonomy(c, filterEventsByRegion(c, query))
+
+	if value := c.Query("location_id"); value != "" {
+		query = query.Where("events.location_id = ?", value)
+	}
+	if value := c.Query("mode"); value != "" {
+		query = query.Where("events.mode = ?", value)
+	}
+	if value := c.Query("status"); value != "" {
+		query = query.Where("events.status IN ?", strings.Split(value, ","))
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, err := parseFilterTime(value, false)
+		if err != nil {
+			return nil, errors.New("Invalid from date")
+		}
+		query = query.Where("COALESCE(events.ends_at, events.starts_at) >= ?", from)
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := parseFilterTime(value, true)
+		if err != nil {
+			return nil, errors.New("Invalid to date")
+		}
+		query = query.Where("events.starts_at < ?", to)
+	}
+
+	if value := c.Query("min_price"); value != "" {
+		minPrice, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("Invalid min_price")
+		}
+		query = query.Where(eventPriceSQL+" >= ?", minPrice)
+	}
+	if value := c.Query("max_price"); value != "" {
+		maxPrice, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("Invalid max_price")
+		}
+		query = query.Where(eventPriceSQL+" <= ?", maxPrice)
+	}
+	if free, _ := strconv.ParseBool(c.Query("free")); free {
+		query = query.Where(eventPriceSQL + " = 0")
+	}
+	if available, _ := strconv.ParseBool(c.Query("available")); available {
+		query = query.Where("events.remaining_capacity > 0")
+	}
+	return query, nil
+}
+
+func encodeCursor(value string, id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d|%s", id, value)))
+}
+
+func decodeCursor(cursor string) (string, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, err
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return "", 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	return parts[1], uint(id), err
+}
+
+// urutkan dan potong query daftar event. dengan ?cursor= (atau ?pagination=cursor) dipakai
+// keyset pagination, selain itu ?page= & ?per_page=. metadata ditulis ke header
+func paginateEvents(c *gin.Context, query *gorm.DB, defaultSort string, defaultPerPage int) ([]models.Event, eventPage, bool) {
+	page := pageFromQuery(c, defaultPerPage)
+
+	sort, err := parseSort(c.Query("sort"), defaultSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, page, false
+	}
+
+	query, err = applyEventFilters(c, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, page, false
+	}
+
+	if err := query.Session(&gorm.Session{}).Model(&models.Event{}).Count(&page.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count events"})
+		return nil, page, false
+	}
+
+	direction, compare := "ASC", ">"
+	if sort.desc {
+		direction, compare = "DESC", "<"
+	}
+	query = query.Order(fmt.Sprintf("%s %s, events.id %s", sort.expr, direction, direction))
+
+	cursor := c.Query("cursor")
+	page.cursorMode = cursor != "" || c.Query("pagination") == "cursor"
+	if cursor != "" {
+		value, id, err := decodeCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return nil, page, false
+		}
+		query = query.Where(fmt.Sprintf("(%s, events.id) %s (CAST(? AS %s), ?)", sort.expr, compare, sort.cast), value, id)
+	} else if !page.cursorMode {
+		query = query.Offset((page.Page - 1) * page.PerPage)
+	}
+
+	// satu baris lebih untuk tahu apakah masih ada halaman berikutnya
+	var events []models.Event
+	if err := query.Limit(page.PerPage + 1).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return nil, page, false
+	}
+
+	page.HasMore = len(events) > page.PerPage
+	if page.HasMore {
+		events = events[:page.PerPage]
+		if page.cursorMode {
+			last := events[len(events)-1]
+			var value string
+			database.DB.Table("events").Select("("+sort.expr+")::text").Where("events.id = ?", last.ID).Scan(&value)
+			page.NextCursor = encodeCursor(value, last.ID)
+		}
+	}
+
+	writePageHeaders(c, page)
+	return events, page, true
+}
+
+func pageURL(c *gin.Context, set map[string]string) string {
+	values := url.Values{}
+	for key, v := range c.Request.URL.Query() {
+		values[key] = v
+	}
+	for key, value := range set {
+		if value == "" {
+			values.Del(key)
+		} else {
+			values.Set(key, value)
+		}
+	}
+	return fmt.Sprintf("<%s?%s>", c.Request.URL.Path, values.Encode())
+}
+
+func writePageHeaders(c *gin.Context, page eventPage) {
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	c.Header("X-Per-Page", strconv.Itoa(page.PerPage))
+
+	var links []string
+	if page.cursorMode {
+		if page.HasMore {
+			c.Header("X-Next-Cursor", page.NextCursor)
+			links = append(links, pageURL(c, map[string]string{"cursor": page.NextCursor})+`; rel="next"`)
+		}
+	} else {
+		totalPages := int((page.Total + int64(page.PerPage) - 1) / int64(page.PerPage))
+		c.Header("X-Page", strconv.Itoa(page.Page))
+		c.Header("X-Total-Pages", strconv.Itoa(totalPages))
+
+		links = append(links, pageURL(c, map[string]string{"page": "1"})+`; rel="first"`)
+		if page.Page > 1 {
+			links = append(links, pageURL(c, map[string]string{"page": strconv.Itoa(page.Page - 1)})+`; rel="prev"`)
+		}
+		if page.HasMore {
+			links = append(links, pageURL(c, map[string]string{"page": strconv.Itoa(page.Page + 1)})+`; rel="next"`)
+		}
+		if totalPages > 0 {
+			links = append(links, pageURL(c, map[string]string{"page": strconv.Itoa(totalPages)})+`; rel="last"`)
+		}
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
@@ -5,6 +5,7 @@ import (
     "github.com/gin-gonic/gin"
     "backend-event/models"
     "backend-event/database"
+    "gorm.io/gorm"
 )
 
 // Create Location
@@ -15,6 +16,16 @@ func CreateLocation(c *gin.Context) {
         return
     }
 
+    location.ID = 0
+    if existing, err := prepareLocation(&location); err != nil {
+        if existing != nil {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "data": existing})
+            return
+        }
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     if err := database.DB.Create(&location).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create location"})
         return
@@ -25,13 +36,22 @@ func CreateLocation(c *gin.Context) {
 
 // Get All Locations
 func GetAllLocations(c *gin.Context) {
+    modified := tablesLastModified("locations")
+    if notModifiedSince(c, modified, taxonomyCacheControl) {
+        return
+    }
+
     var locations []models.Location
-    if err := database.DB.Find(&locations).Error; err != nil {
+    query := database.DB.Order("city")
+    if provinceCode := c.Query("province_code"); provinceCode != "" {
+        query = query.Where("province_code = ?", provinceCode)
+    }
+    if err := query.Find(&locations).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve locations"})
         return
     }
 
-    c.JSON(http.StatusOK, locations)
+    respondCached(c, locations, modified, taxonomyCacheControl, "")
 }
 
 // Get Location by ID
@@ -58,7 +78,10 @@ func UpdateLocation(c *gin.Context) {
     }
 
     var input struct {
-        City string `json:"city"`
+        City         string `json:"city"`
+        ProvinceCode string `json:"province_code"`
+        RegencyCode  string `json:"regency_code"`
+        DistrictCode string `json:"district_code"`
     }
 
     if err := c.ShouldBindJSON(&input); err != nil {
@@ -67,10 +90,23 @@ func UpdateLocation(c *gin.Context) {
     }
 
     location.City = input.City
+    location.ProvinceCode = input.ProvinceCode
+    location.RegencyCode = input.RegencyCode
+    location.DistrictCode = input.DistrictCode
+    if existing, err := prepareLocation(&location); err != nil {
+        if existing != nil {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "data": existing})
+            return
+        }
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     if err := database.DB.Save(&location).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update location"})
         return
     }
+    database.DB.Model(&models.Event{}).Where("location_id = ?", location.ID).Update("location", location.City)
 
     c.JSON(http.StatusOK, location)
 }
@@ -78,7 +114,51 @@ func UpdateLocation(c *gin.Context) {
 // Delete Location
 func DeleteLocation(c *gin.Context) {
     id := c.Param("id")
-    if err := database.DB.Delete(&models.Location{}, id).Error; err != nil {
+    var location models.Location
+    if err := database.DB.First(&location, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Location not found"})
+        return
+    }
+
+    // lokasi yang masih dipakai event atau venue harus dipindah dulu dengan reassign_to
+    events, err := countEvents("location_id", location.ID)
+    var venues int64
+    if err == nil {
+        err = database.DB.Model(&models.Venue{}).Where("location_id = ?", location.ID).Count(&venues).Error
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check related events"})
+        return
+    }
+
+    var target models.Location
+    exists := func(id uint) bool {
+        return database.DB.First(&target, id).Error == nil
+    }
+    to, ok := reassignTarget(c, location.ID, events, "events", exists)
+    if ok && to == 0 {
+        to, ok = reassignTarget(c, location.ID, venues, "venues", exists)
+    }
+    if !ok {
+        return
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if to != 0 {
+            if err := moveEvents(tx, "location_id", location.ID, to); err != nil {
+                return err
+            }
+            if err := tx.Model(&models.Event{}).Where("location_id = ?", to).Update("location", target.City).Error; err != nil {
+                return err
+            }
+            if err := tx.Model(&models.Venue{}).Where("location_id = ?", location.ID).
+                Updates(map[string]interface{}{"location_id": to, "city": target.City}).Error; err != nil {
+                return err
+            }
+        }
+        return tx.Delete(&location).Error
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete location"})
         return
     }
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNearbyRadiusKm = 10
+	maxNearbyRadiusKm     = 500
+	defaultNearbyLimit    = 50
+	maxNearbyLimit        = 200
+)
+
+// jarak haversine dalam km dari titik (?, ?) ke koordinat venue
+const venueDistanceSQL = `? * 2 * ASIN(LEAST(1, SQRT(
+	POWER(SIN(RADIANS(venues.latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(venues.latitude)) * POWER(SIN(RADIANS(venues.longitude - ?) / 2), 2))))`
+
+type nearbyEvent struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	StartsAt   *time.Time `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	Timezone   string     `json:"timezone"`
+	Photo      string     `json:"photo"`
+	Price      string     `json:"price"`
+	Status     string     `json:"status"`
+	VenueID    uint       `json:"venue_id"`
+	VenueName  string     `json:"venue_name"`
+	Address    string     `json:"address"`
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	DistanceKm float64    `json:"distance_km"`
+}
+
+type nearbyQuery struct {
+	Lat, Lng    float64
+	RadiusKm    float64
+	Box         models.BoundingBox
+	Limit       int
+	IncludePast bool
+}
+
+func queryFloat(c *gin.Context, name string) (float64, bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, false, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, true, errors.New("Invalid " + name)
+	}
+	return f, true, nil
+}
+
+// baca lat/lng + radius_km, atau kotak peta min_lat/min_lng/max_lat/max_lng
+func bindNearbyQuery(c *gin.Context) (nearbyQuery, error) {
+	query := nearbyQuery{RadiusKm: defaultNearbyRadiusKm, Limit: defaultNearbyLimit}
+
+	lat, hasLat, err := queryFloat(c, "lat")
+	if err != nil {
+		return query, err
+	}
+	lng, hasLng, err := queryFloat(c, "lng")
+	if err != nil {
+		return query, err
+	}
+	if hasLat != hasLng {
+		return query, errors.New("lat and lng must be set together")
+	}
+
+	var bounds [4]float64
+	hasBox := 0
+	for i, name := range []string{"min_lat", "min_lng", "max_lat", "max_lng"} {
+		value, ok, err := queryFloat(c, name)
+		if err != nil {
+			return query, err
+		}
+		if ok {
+			bounds[i] = value
+			hasBox++
+		}
+	}
+
+	switch {
+	case hasBox == 4:
+		query.Box = models.BoundingBox{MinLat: bounds[0], MinLng: bounds[1], MaxLat: bounds[2], MaxLng: bounds[3]}
+		if !query.Box.Valid() {
+			return query, errors.New("Invalid bounding box")
+		}
+		query.RadiusKm = 0
+		query.Lat, query.Lng = query.Box.Center()
+		if hasLat {
+			query.Lat, query.Lng = lat, lng
+		}
+	case hasBox > 0:
+		return query, errors.New("min_lat, min_lng, max_lat and max_lng must be set together")
+	case hasLat:
+		if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			return query, errors.New("Invalid coordinates")
+		}
+		if radius, ok, err := queryFloat(c, "radius_km"); err != nil {
+			return query, err
+		} else if ok {
+			if radius <= 0 || radius > maxNearbyRadiusKm {
+				return query, errors.New("radius_km must be between 0 and 500")
+			}
+			query.RadiusKm = radius
+		}
+		query.Lat, query.Lng = lat, lng
+		query.Box = models.BoundingBoxAround(lat, lng, query.RadiusKm)
+	default:
+		return query, errors.New("lat and lng or a bounding box is required")
+	}
+
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 {
+		query.Limit = int(math.Min(float64(limit), maxNearbyLimit))
+	}
+	query.IncludePast, _ = strconv.ParseBool(c.Query("include_past"))
+	return query, nil
+}
+
+// event publik yang venuenya ada di dalam kotak, kotak memakai index koordinat venue
+func nearbyCandidates(query nearbyQuery) *gorm.DB {
+	db := database.DB.Table("events").
+		Joins("JOIN venues ON venues.id = events.venue_id AND venues.deleted_at IS NULL").
+		Where("events.deleted_at IS NULL AND events.visibility = ? AND events.publication_status = ?", "public", models.PublicationPublished).
+		Where("venues.latitude BETWEEN ? AND ? AND venues.longitude BETWEEN ? AND ?",
+			query.Box.MinLat, query.Box.MaxLat, query.Box.MinLng, query.Box.MaxLng)
+	if !query.IncludePast {
+		db = db.Where("events.ends_at IS NULL OR events.ends_at >= ?", time.Now())
+	}
+	return db
+}
+
+const nearbyColumns = `events.id, events.name, events.starts_at, events.ends_at, events.timezone, events.photo,
+	events.price, events.status, venues.id AS venue_id, venues.name AS venue_name, venues.address,
+	venues.latitude, venues.longitude`
+
+// jarak dihitung di PostgreSQL, hasil di luar radius dibuang setelah prefilter kotak
+func findNearbyEventsSQL(query nearbyQuery) ([]nearbyEvent, error) {
+	inner := nearbyCandidates(query).
+		Select(nearbyColumns+", "+venueDistanceSQL+" AS distance_km", models.EarthRadiusKm, query.Lat, query.Lat, query.Lng)
+
+	db := database.DB.Table("(?) AS nearby", inner)
+	if query.RadiusKm > 0 {
+		db = db.Where("distance_km <= ?", query.RadiusKm)
+	}
+
+	var events []nearbyEvent
+	err := db.Order("distance_km, starts_at").Limit(query.Limit).Scan(&events).Error
+	return events, err
+}
+
+// cadangan untuk database selain PostgreSQL (misalnya sqlite saat testing): jarak dihitung di Go
+func findNearbyEventsInMemory(query nearbyQuery) ([]nearbyEvent, error) {
+	var events []nearbyEvent
+	if err := nearbyCandidates(query).Select(nearbyColumns).Scan(&events).Error; err != nil {
+		return nil, err
+	}
+
+	nearby := events[:0]
+	for _, event := range events {
+		event.DistanceKm = models.DistanceKm(query.Lat, query.Lng, event.Latitude, event.Longitude)
+		if query.RadiusKm == 0 || event.DistanceKm <= query.RadiusKm {
+			nearby = append(nearby, event)
+		}
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].DistanceKm < nearby[j].DistanceKm })
+	if len(nearby) > query.Limit {
+		nearby = nearby[:query.Limit]
+	}
+	return nearby, nil
+}
+
+// event terdekat dari lat/lng dalam radius_km (default 10), atau di dalam kotak peta,
+// diurutkan dari yang paling dekat
+func GetNearbyEvents(c *gin.Context) {
+	query, err := bindNearbyQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var events []nearbyEvent
+	if database.DB.Dialector.Name() == "postgres" {
+		events, err = findNearbyEventsSQL(query)
+	} else {
+		events, err = findNearbyEventsInMemory(query)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search events"})
+		return
+	}
+
+	now := time.Now()
+	for i := range events {
+		events[i].DistanceKm = math.Round(events[i].DistanceKm*100) / 100
+		event := models.Event{Status: events[i].Status, StartsAt: events[i].StartsAt, EndsAt: events[i].EndsAt, Timezone: events[i].Timezone}
+		events[i].Status = event.ComputeStatus(now)
+	}
+	if events == nil {
+		events = []nearbyEvent{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"center":    gin.H{"lat": query.Lat, "lng": query.Lng},
+		"radius_km": query.RadiusKm,
+		"bbox":      query.Box,
+		"events":    events,
+	})
+}
@@ -3,8 +3,8 @@ package controllers
 import (
 	"backend-event/database"
 	"backend-event/models"
+	"backend-event/serializers"
 	"github.com/gin-gonic/gin"
-	"math"
 	"net/http"
 )
 
@@ -12,7 +12,7 @@ func UpdatePopularityScore(eventID uint) error {
 	var totalRegistrations int64
 	var averageRating float64
 
-	if err := database.DB.Model(&models.Registration{}).Where("event_id = ?", eventID).Count(&totalRegistrations).Error; err != nil {
+	if err := database.DB.Model(&models.Registration{}).Where("event_id = ? AND status = ?", eventID, "approved").Count(&totalRegistrations).Error; err != nil {
 		return err
 	}
 
@@ -30,95 +30,21 @@ func UpdatePopularityScore(eventID uint) error {
 }
 
 func GetPopularEvents(c *gin.Context) {
-	var events []models.Event
-
-	if err := database.DB.Order("popularity_score DESC").Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar event populer"})
+	shape, ok := parseResponseShape(c, serializers.EventRelations, eventListInclude...)
+	if !ok {
 		return
 	}
 
-	var response []struct {
-		ID                uint    `json:"id"`
-		Name              string  `json:"name"`
-		Description       string  `json:"description"`
-		DateStart         string  `json:"date_start"`
-		DateEnd           string  `json:"date_end"`
-		Time              string  `json:"time"`
-		Location          string  `json:"location"`
-		Address           string  `json:"address"`
-		Capacity          int     `json:"capacity"`
-		RemainingCapacity int     `json:"remaining_capacity"`
-		Photo             string  `json:"photo"`
-		Price             string  `json:"price"`
-		Category          string  `json:"category"`
-		AverageRating     float64 `json:"average_rating"`
-		UniqueRaters      int64   `json:"unique_raters"`
-		PopularityScore   float64 `json:"popularity_score"`
+	query := shape.preload(database.DB, "").Where("visibility = ? AND publication_status = ?", "public", models.PublicationPublished)
+	events, _, ok := paginateEvents(c, query, "popularity", defaultPerPage)
+	if !ok {
+		return
 	}
 
-	for _, event := range events {
-		var location models.Location
-		if err := database.DB.First(&location, event.LocationID).Error; err != nil {
-			location.City = ""
-		}
-
-		var category models.Category
-		if err := database.DB.First(&category, event.CategoryID).Error; err != nil {
-			category.Name = ""
-		}
-
-		var averageRating float64
-		if err := database.DB.Model(&models.Rating{}).
-			Where("event_id = ?", event.ID).
-			Select("COALESCE(AVG(rating), 0)").Scan(&averageRating).Error; err != nil {
-			averageRating = 0.0
-		}
-
-		averageRating = math.Round(averageRating*100) / 100
-
-		var uniqueRaters int64
-		if err := database.DB.Model(&models.Rating{}).
-			Where("event_id = ?", event.ID).
-			Select("COUNT(DISTINCT user_id)").Scan(&uniqueRaters).Error; err != nil {
-			uniqueRaters = 0
-		}
-
-		response = append(response, struct {
-			ID                uint    `json:"id"`
-			Name              string  `json:"name"`
-			Description       string  `json:"description"`
-			DateStart         string  `json:"date_start"`
-			DateEnd           string  `json:"date_end"`
-			Time              string  `json:"time"`
-			Location          string  `json:"location"`
-			Address           string  `json:"address"`
-			Capacity          int     `json:"capacity"`
-			RemainingCapacity int     `json:"remaining_capacity"`
-			Photo             string  `json:"photo"`
-			Price             string  `json:"price"`
-			Category          string  `json:"category"`
-			AverageRating     float64 `json:"average_rating"`
-			UniqueRaters      int64   `json:"unique_raters"`
-			PopularityScore   float64 `json:"popularity_score"`
-		}{
-			ID:                event.ID,
-			Name:              event.Name,
-			Description:       event.Description,
-			DateStart:         event.DateStart,
-			DateEnd:           event.DateEnd,
-			Time:              event.Time,
-			Location:          location.City,
-			Address:           event.Address,
-			Capacity:          event.Capacity,
-			RemainingCapacity: event.RemainingCapacity,
-			Photo:             event.Photo,
-			Price:             event.Price,
-			Category:          category.Name,
-			AverageRating:     averageRating,
-			UniqueRaters:      uniqueRaters,
-			PopularityScore:   event.PopularityScore,
-		})
+	result, err := serializeEvents(events, shape)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
 	}
-
-	c.JSON(http.StatusOK, response)
+	shape.respond(c, http.StatusOK, result)
 }
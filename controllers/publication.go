@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/scheduler"
+	"backend-event/serializers"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func currentUser(c *gin.Context) (models.User, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		return models.User{}, false
+	}
+	loggedInUser, ok := user.(models.User)
+	return loggedInUser, ok
+}
+
+// admin boleh kelola semua event, organizer hanya event miliknya
+func canManageEvent(user models.User, event models.Event) bool {
+	if user.Role == "admin" {
+		return true
+	}
+	return event.OrganizerID != nil && *event.OrganizerID == user.ID
+}
+
+// event yang bisa dikelola user login, 404 kalau bukan miliknya
+func findManagedEvent(c *gin.Context) (models.Event, models.User, bool) {
+	return findManagedEventParam(c, "id")
+}
+
+// sama dengan findManagedEvent untuk route yang id event-nya bukan :id (misalnya :event_id)
+func findManagedEventParam(c *gin.Context, param string) (models.Event, models.User, bool) {
+	var event models.Event
+
+	user, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return event, user, false
+	}
+
+	if err := database.DB.First(&event, c.Param(param)).Error; err != nil || !canManageEvent(user, event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return event, user, false
+	}
+
+	return event, user, true
+}
+
+// jadwalkan publikasi, langsung tayang kalau publish_at kosong atau sudah lewat
+func schedulePublication(event *models.Event, publishAt *time.Time, now time.Time) {
+	event.PublishAt = publishAt
+	event.ReviewNote = ""
+	if publishAt != nil && publishAt.After(now) {
+		event.PublicationStatus = models.PublicationScheduled
+		return
+	}
+	event.PublicationStatus = models.PublicationPublished
+}
+
+// baca status publikasi dari form saat membuat event: default draft,
+// submit=true langsung diajukan ke review (admin langsung publish)
+func bindEventPublication(c *gin.Context, event *models.Event) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return errors.New("Unauthorized")
+	}
+	event.OrganizerID = &user.ID
+	event.PublicationStatus = models.PublicationDraft
+
+	var publishAt *time.Time
+	if value := c.PostForm("publish_at"); value != "" {
+		parsed, err := models.ParseTimestamp(value, event.TimeLocation())
+		if err != nil {
+			return errors.New("Invalid publish_at format")
+		}
+		publishAt = &parsed
+	}
+	event.PublishAt = publishAt
+
+	if submit, _ := strconv.ParseBool(c.PostForm("submit")); submit {
+		if user.Role == "admin" {
+			schedulePublication(event, publishAt, time.Now())
+		} else {
+			event.PublicationStatus = models.PublicationInReview
+		}
+	}
+	return nil
+}
+
+func savePublication(c *gin.Context, event models.Event, message string) {
+	if err := database.DB.Model(&event).Updates(map[string]interface{}{
+		"publication_status": event.PublicationStatus,
+		"publish_at":         event.PublishAt,
+		"review_note":        event.ReviewNote,
+		"version":            gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event publication"})
+		return
+	}
+
+	if event.PublicationStatus == models.PublicationScheduled {
+		scheduler.Wake()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            message,
+		"publication_status": event.PublicationStatus,
+		"publish_at":         event.PublishAt,
+		"review_note":        event.ReviewNote,
+	})
+}
+
+type publishInput struct {
+	PublishAt string `json:"publish_at"`
+}
+
+func (input publishInput) time(event models.Event) (*time.Time, error) {
+	if input.PublishAt == "" {
+		return nil, nil
+	}
+	parsed, err := models.ParseTimestamp(input.PublishAt, event.TimeLocation())
+	if err != nil {
+		return nil, errors.New("Invalid publish_at format")
+	}
+	return &parsed, nil
+}
+
+// ajukan draft ke admin untuk direview
+func SubmitEvent(c *gin.Context) {
+	event, user, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	if event.PublicationStatus != models.PublicationDraft && event.PublicationStatus != models.PublicationRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only draft or rejected events can be submitted"})
+		return
+	}
+
+	var input publishInput
+	c.ShouldBindJSON(&input)
+
+	publishAt, err := input.time(event)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if user.Role == "admin" {
+		schedulePublication(&event, publishAt, time.Now())
+		savePublication(c, event, "Event published")
+		return
+	}
+
+	event.PublicationStatus = models.PublicationInReview
+	event.PublishAt = publishAt
+	event.ReviewNote = ""
+	savePublication(c, event, "Event submitted for review")
+}
+
+// admin menyetujui event, tayang sekarang atau pada publish_at
+func PublishEvent(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	if event.PublicationStatus == models.PublicationPublished {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event is already published"})
+		return
+	}
+
+	var input publishInput
+	c.ShouldBindJSON(&input)
+
+	publishAt, err := input.time(event)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if publishAt == nil {
+		publishAt = event.PublishAt
+	}
+
+	schedulePublication(&event, publishAt, time.Now())
+
+	message := "Event published"
+	if event.PublicationStatus == models.PublicationScheduled {
+		message = "Event scheduled for publication"
+	}
+	savePublication(c, event, message)
+}
+
+// admin menolak event dengan catatan untuk organizer
+func RejectEvent(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	if event.PublicationStatus != models.PublicationInReview {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only events in review can be rejected"})
+		return
+	}
+
+	var input struct {
+		Note string `json:"note"`
+	}
+	c.ShouldBindJSON(&input)
+
+	event.PublicationStatus = models.PublicationRejected
+	event.ReviewNote = input.Note
+	savePublication(c, event, "Event rejected")
+}
+
+// tarik event kembali ke draft
+func UnpublishEvent(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	if event.PublicationStatus == models.PublicationDraft {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event is already a draft"})
+		return
+	}
+
+	event.PublicationStatus = models.PublicationDraft
+	event.PublishAt = nil
+	savePublication(c, event, "Event moved back to draft")
+}
+
+// lihat event apa adanya termasuk yang belum tayang
+func PreviewEvent(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("event_id = ?", event.ID).Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	response, err := managedEventResponse(event, sessions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// semua event milik organizer, termasuk draft
+func GetMyEvents(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	shape, ok := parseResponseShape(c, serializers.EventRelations, managedListInclude...)
+	if !ok {
+		return
+	}
+
+	query := shape.preload(database.DB, "").Where("organizer_id = ?", user.ID)
+	if status := c.Query("publication_status"); status != "" {
+		query = query.Where("publication_status = ?", status)
+	}
+
+	var events []models.Event
+	if err := query.Order("id DESC").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	result, err := serializeManagedEvents(events, shape)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	shape.respond(c, http.StatusOK, result)
+}
+
+// antrean event yang menunggu review admin
+func GetReviewQueue(c *gin.Context) {
+	shape, ok := parseResponseShape(c, serializers.EventRelations, managedListInclude...)
+	if !ok {
+		return
+	}
+
+	var events []models.Event
+	if err := shape.preload(database.DB, "").Where("publication_status = ?", models.PublicationInReview).Order("id").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	result, err := serializeManagedEvents(events, shape)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	shape.respond(c, http.StatusOK, result)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"backend-event/database"
 	"backend-event/models"
+	"backend-event/serializers"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"log"
@@ -67,7 +68,7 @@ func GetEventRatings(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ratings": ratings})
+	c.JSON(http.StatusOK, gin.H{"ratings": serializers.NewRatings(ratings)})
 }
 
 func UpdateRating(c *gin.Context) {
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/regions"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type regencyEventCount struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	EventCount int64  `json:"event_count"`
+}
+
+type provinceEventCount struct {
+	Code       string               `json:"code"`
+	Name       string               `json:"name"`
+	EventCount int64                `json:"event_count"`
+	Regencies  []*regencyEventCount `json:"regencies"`
+}
+
+// kode provinsi dari kode atau nama ("31", "DKI Jakarta", "Jakarta")
+func resolveProvinceCode(value string) string {
+	var province models.Province
+	if database.DB.Where("code = ?", value).First(&province).Error == nil {
+		return province.Code
+	}
+
+	var provinces []models.Province
+	database.DB.Find(&provinces)
+	for _, province := range provinces {
+		if regions.NormalizeName(province.Name) == regions.NormalizeName(value) {
+			return province.Code
+		}
+	}
+	return ""
+}
+
+// kode kabupaten/kota dari kode atau nama ("31.71", "Kota Bandung")
+func resolveRegencyCode(value string) string {
+	var regency models.Regency
+	if database.DB.Where("code = ?", value).First(&regency).Error == nil {
+		return regency.Code
+	}
+	_, code, _ := regions.Match(database.DB, value)
+	return code
+}
+
+// filter ?province= dan ?city= (kode atau nama wilayah) untuk daftar event
+func filterEventsByRegion(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if value := c.Query("province"); value != "" {
+		query = query.Where("events.location_id IN (?)", database.DB.Model(&models.Location{}).
+			Select("id").
+			Where("province_code = ?", resolveProvinceCode(value)))
+	}
+
+	if value := c.Query("city"); value != "" {
+		locations := database.DB.Model(&models.Location{}).Select("id")
+		if code := resolveRegencyCode(value); code != "" {
+			locations = locations.Where("regency_code = ?", code)
+		} else {
+			locations = locations.Where("city ILIKE ?", value)
+		}
+		query = query.Where("events.location_id IN (?)", locations)
+	}
+	return query
+}
+
+// lengkapi kode wilayah location dan tolak kota yang sudah ada.
+// kalau duplikat, location yang sudah ada ikut dikembalikan
+func prepareLocation(location *models.Location) (*models.Location, error) {
+	location.City = strings.TrimSpace(location.City)
+
+	if location.DistrictCode != "" {
+		var district models.District
+		if err := database.DB.Where("code = ?", location.DistrictCode).First(&district).Error; err != nil {
+			return nil, errors.New("Invalid district code")
+		}
+		location.RegencyCode = district.RegencyCode
+	}
+
+	switch {
+	case location.RegencyCode != "":
+		var regency models.Regency
+		if err := database.DB.Where("code = ?", location.RegencyCode).First(&regency).Error; err != nil {
+			return nil, errors.New("Invalid regency code")
+		}
+		location.ProvinceCode = regency.ProvinceCode
+		if location.City == "" {
+			location.City = regency.Name
+		}
+	case location.ProvinceCode != "":
+		if err := database.DB.Where("code = ?", location.ProvinceCode).First(&models.Province{}).Error; err != nil {
+			return nil, errors.New("Invalid province code")
+		}
+	default:
+		provinceCode, regencyCode, err := regions.Match(database.DB, location.City)
+		if err != nil {
+			return nil, err
+		}
+		location.ProvinceCode, location.RegencyCode = provinceCode, regencyCode
+	}
+
+	if location.City == "" {
+		return nil, errors.New("City is required")
+	}
+
+	var others []models.Location
+	if err := database.DB.Where("id <> ?", location.ID).Find(&others).Error; err != nil {
+		return nil, err
+	}
+	for i, other := range others {
+		sameRegion := location.RegencyCode != "" && other.RegencyCode == location.RegencyCode && other.DistrictCode == location.DistrictCode
+		sameName := regions.NormalizeName(other.City) == regions.NormalizeName(location.City) &&
+			other.DistrictCode == location.DistrictCode
+		if sameRegion || sameName {
+			return &others[i], errors.New("Location already exists")
+		}
+	}
+	return nil, nil
+}
+
+// ?q= mencari nama provinsi
+func GetProvinces(c *gin.Context) {
+	query := database.DB.Order("code")
+	if q := c.Query("q"); q != "" {
+		query = query.Where("name ILIKE ?", "%"+q+"%")
+	}
+
+	provinces := []models.Province{}
+	if err := query.Find(&provinces).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch provinces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, provinces)
+}
+
+func GetRegencies(c *gin.Context) {
+	query := database.DB.Where("province_code = ?", c.Param("code")).Order("code")
+	if q := c.Query("q"); q != "" {
+		query = query.Where("name ILIKE ?", "%"+q+"%")
+	}
+
+	regencies := []models.Regency{}
+	if err := query.Find(&regencies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch regencies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regencies)
+}
+
+func GetDistricts(c *gin.Context) {
+	query := database.DB.Where("regency_code = ?", c.Param("code")).Order("code")
+	if q := c.Query("q"); q != "" {
+		query = query.Where("name ILIKE ?", "%"+q+"%")
+	}
+
+	districts := []models.District{}
+	if err := query.Find(&districts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch districts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, districts)
+}
+
+// impor ulang data wilayah dari dataset offline (REGION_DATA_DIR atau data bawaan)
+func ImportRegions(c *gin.Context) {
+	counts, err := regions.Import(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import regions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Regions imported", "data": counts})
+}
+
+// jumlah event publik yang belum selesai per provinsi dan kabupaten/kota
+func GetRegionEventCounts(c *gin.Context) {
+	var rows []struct {
+		ProvinceCode string
+		RegencyCode  string
+		EventCount   int64
+	}
+	if err := database.DB.Table("events").
+		Select("locations.province_code, locations.regency_code, COUNT(DISTINCT events.id) AS event_count").
+		Joins("JOIN locations ON locations.id = events.location_id AND locations.deleted_at IS NULL").
+		Where("events.deleted_at IS NULL AND events.visibility = ? AND events.publication_status = ?", "public", models.PublicationPublished).
+		Where("events.ends_at IS NULL OR events.ends_at >= ?", time.Now()).
+		Where("locations.province_code <> ''").
+		Group("locations.province_code, locations.regency_code").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count events"})
+		return
+	}
+
+	var provinces []models.Province
+	var regencies []models.Regency
+	database.DB.Find(&provinces)
+	database.DB.Find(&regencies)
+
+	provinceNames := make(map[string]string, len(provinces))
+	for _, province := range provinces {
+		provinceNames[province.Code] = province.Name
+	}
+	regencyNames := make(map[string]string, len(regencies))
+	for _, regency := range regencies {
+		regencyNames[regency.Code] = regency.Name
+	}
+
+	byCode := make(map[string]*provinceEventCount)
+	result := []*provinceEventCount{}
+	for _, row := range rows {
+		province, ok := byCode[row.ProvinceCode]
+		if !ok {
+			province = &provinceEventCount{Code: row.ProvinceCode, Name: provinceNames[row.ProvinceCode], Regencies: []*regencyEventCount{}}
+			byCode[row.ProvinceCode] = province
+			result = append(result, province)
+		}
+		province.EventCount += row.EventCount
+		if row.RegencyCode != "" {
+			province.Regencies = append(province.Regencies, &regencyEventCount{
+				Code:       row.RegencyCode,
+				Name:       regencyNames[row.RegencyCode],
+				EventCount: row.EventCount,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].EventCount > result[j].EventCount })
+	for _, province := range result {
+		sort.Slice(province.Regencies, func(i, j int) bool {
+			return province.Regencies[i].EventCount > province.Regencies[j].EventCount
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"backend-event/database"
 	"backend-event/models"
+	"errors"
 	"net/http"
 	"log"
 	"fmt"
@@ -18,7 +19,7 @@ func RegisterEvent(c *gin.Context) {
 	eventID := c.Param("event_id")
 	var event models.Event
 
-	if err := database.DB.First(&event, eventID).Error; err != nil {
+	if err := database.DB.First(&event, eventID).Error; err != nil || !event.IsPublished() {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event tidak ditemukan"})
 		return
 	}
@@ -41,7 +42,7 @@ func RegisterEvent(c *gin.Context) {
 	}
 
 	var userEvent models.Registration
-	if err := database.DB.Where("user_id = ? AND event_id = ?", loggedInUser.ID, event.ID).First(&userEvent).Error; err == nil {
+	if err := database.DB.Where("user_id = ? AND event_id = ? AND group_id IS NULL", loggedInUser.ID, event.ID).First(&userEvent).Error; err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Anda sudah terdaftar untuk event ini"})
 		return
 	}
@@ -52,6 +53,8 @@ func RegisterEvent(c *gin.Context) {
 		Phone         string `json:"phone"`
 		Job           string `json:"job"`
 		PaymentMethod string `json:"payment_method"`
+		AccessCode    string `json:"access_code"`
+		InviteToken   string `json:"invite_token"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -59,11 +62,30 @@ func RegisterEvent(c *gin.Context) {
 		return
 	}
 
+	invitation, ok := checkEventAccess(event, input.AccessCode, input.InviteToken)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Event ini hanya untuk undangan, kode akses atau undangan tidak valid"})
+		return
+	}
+	if invitation != nil && invitation.Status == "accepted" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Undangan sudah digunakan"})
+		return
+	}
+
 	if strings.ToLower(event.Price) != "free" && input.PaymentMethod == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Metode pembayaran diperlukan untuk event berbayar"})
 		return
 	}
 
+	conflicts, err := findScheduleConflicts(loggedInUser.ID, event)
+	if err != nil {
+		log.Printf("Gagal memeriksa jadwal bentrok: %v", err)
+	}
+	if len(conflicts) > 0 && blockScheduleConflicts() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Jadwal event bentrok dengan event lain yang sudah Anda daftarkan", "conflicts": conflicts})
+		return
+	}
+
 	tx := database.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -71,8 +93,19 @@ func RegisterEvent(c *gin.Context) {
 		}
 	}()
 
+	status := "approved"
+	if event.RequiresApproval {
+		status = "pending"
+	}
+
+	ticketCode, err := generateTicketCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kode tiket"})
+		return
+	}
+
 	userEvent = models.Registration{
-		UserID:        loggedInUser.ID,
+		UserID:        &loggedInUser.ID,
 		EventID:       event.ID,
 		Name:          input.Name,
 		Username:      loggedInUser.Username,
@@ -80,6 +113,8 @@ func RegisterEvent(c *gin.Context) {
 		PhoneNumber:   input.Phone,
 		Job:           input.Job,
 		PaymentMethod: input.PaymentMethod,
+		Status:        status,
+		TicketCode:    ticketCode,
 	}
 
 	if err := tx.Create(&userEvent).Error; err != nil {
@@ -88,22 +123,46 @@ func RegisterEvent(c *gin.Context) {
 		return
 	}
 
-	event.RemainingCapacity -= 1
-	if err := tx.Save(&event).Error; err != nil {
+	if invitation != nil {
+		if err := tx.Model(invitation).Update("status", "accepted").Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui status undangan"})
+			return
+		}
+	}
+
+	if status == "pending" {
+		if err := sendRegistrationStatusEmail(userEvent.Email, userEvent.Name, event.Name, status, ""); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email status pendaftaran"})
+			return
+		}
+
+		tx.Commit()
+
+		c.JSON(http.StatusOK, gin.H{"message": "Pendaftaran berhasil, menunggu persetujuan penyelenggara", "status": status, "warnings": conflicts})
+		return
+	}
+
+	if err := reserveSeats(tx, event.ID, 1); err != nil {
 		tx.Rollback()
+		if errors.Is(err, errEventFull) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kapasitas event"})
 		return
 	}
 
 	if strings.ToLower(event.Price) != "free" && input.PaymentMethod != "" {
-		if err := sendPaymentConfirmationEmail(userEvent.Email, userEvent.Name, event.Name, event.Description, event.DateStart, event.Location, input.PaymentMethod); err != nil {
+		if err := sendPaymentConfirmationEmail(userEvent.Email, userEvent.Name, event.Name, event.Description, formatEventDate(event), event.Location, input.PaymentMethod); err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email konfirmasi pembayaran"})
 			return
 		}	
 	}
 
-	if err := sendEmail(userEvent.Email, userEvent.Name, userEvent.PhoneNumber, userEvent.Job, event.Name, event.Location, event.DateStart, event.Description, event.Mode, event.Link, event.Address); err != nil {
+	if err := sendEmail(userEvent.Email, userEvent.Name, userEvent.PhoneNumber, userEvent.Job, event.Name, event.Location, formatEventDate(event), event.Description, event.Mode, event.Link, event.Address, userEvent.TicketCode); err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email konfirmasi pendaftaran"})
 		return
@@ -116,7 +175,7 @@ func RegisterEvent(c *gin.Context) {
 		log.Printf("Gagal memperbarui popularity score: %v", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Berhasil mendaftar untuk event"})
+	c.JSON(http.StatusOK, gin.H{"message": "Berhasil mendaftar untuk event", "status": status, "warnings": conflicts})
 }
 
 
@@ -160,7 +219,7 @@ func sendPaymentConfirmationEmail(to, name, eventName, description, eventDate, e
 	return nil
 }
 
-func sendEmail(to, name, phone, job, eventName, eventLocation, eventDate, description, mode, link, address string) error {
+func sendEmail(to, name, phone, job, eventName, eventLocation, eventDate, description, mode, link, address, ticketCode string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", os.Getenv("SMTP_USER"))
 	m.SetHeader("To", to)
@@ -209,6 +268,7 @@ func sendEmail(to, name, phone, job, eventName, eventLocation, eventDate, descri
 				<p style="color: #666;"><strong>Nama:</strong> %s</p>
 				<p style="color: #666;"><strong>Telepon:</strong> %s</p>
 				<p style="color: #666;"><strong>Pekerjaan:</strong> %s</p>
+				<p style="color: #666;"><strong>Kode Tiket:</strong> %s</p>
 			</div>
 
 			<div style="background-color: #fff3cd; padding: 15px; border-radius: 5px; margin: 15px 0;">
@@ -231,7 +291,7 @@ func sendEmail(to, name, phone, job, eventName, eventLocation, eventDate, descri
 	`, 
 	name, eventName, description, eventDate, mode, 
 	locationTemplate,
-	name, phone, job,
+	name, phone, job, ticketCode,
 	getImportantNotes(mode))
 
 	m.SetBody("text/html", body)
@@ -0,0 +1,315 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/scheduler"
+	"backend-event/serializers"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// field yang berubah dengan sendirinya tidak dicatat di riwayat
+var revisionIgnoredFields = []string{"remaining_capacity", "popularity_score", "status", "version", "updated_at"}
+
+type fieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// snapshot event beserta sesinya dalam bentuk map json
+func eventSnapshot(tx *gorm.DB, eventID uint) (map[string]interface{}, error) {
+	var event models.Event
+	if err := tx.First(&event, eventID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("event_id = ?", eventID).Order("starts_at, id").Find(&event.Sessions).Error; err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+
+	for _, field := range revisionIgnoredFields {
+		delete(snapshot, field)
+	}
+
+	// id sesi berganti setiap kali sesi dibuat ulang, jadi tidak ikut dibandingkan
+	if sessions, ok := snapshot["sessions"].([]interface{}); ok {
+		for _, session := range sessions {
+			if fields, ok := session.(map[string]interface{}); ok {
+				delete(fields, "id")
+				delete(fields, "event_id")
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+func diffSnapshots(old, new map[string]interface{}) []fieldChange {
+	fields := make(map[string]bool)
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range new {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := []fieldChange{}
+	for _, field := range names {
+		if !reflect.DeepEqual(old[field], new[field]) {
+			changes = append(changes, fieldChange{Field: field, Old: old[field], New: new[field]})
+		}
+	}
+	return changes
+}
+
+func latestRevision(tx *gorm.DB, eventID uint) (*models.EventRevision, error) {
+	var revision models.EventRevision
+	err := tx.Where("event_id = ?", eventID).Order("revision DESC").First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// simpan revisi baru kalau ada perubahan dibanding revisi terakhir
+func recordRevision(tx *gorm.DB, eventID uint, author *models.User, note string) (*models.EventRevision, error) {
+	snapshot, err := eventSnapshot(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := latestRevision(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []fieldChange{}
+	number := 1
+	if last != nil {
+		var previous map[string]interface{}
+		if err := json.Unmarshal([]byte(last.Snapshot), &previous); err != nil {
+			return nil, err
+		}
+		changes = diffSnapshots(previous, snapshot)
+		if len(changes) == 0 {
+			return last, nil
+		}
+		number = last.Revision + 1
+	}
+
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := models.EventRevision{
+		EventID:  eventID,
+		Revision: number,
+		Snapshot: string(snapshotJSON),
+		Changes:  string(changesJSON),
+		Note:     note,
+	}
+	if author != nil {
+		revision.AuthorID = &author.ID
+		revision.AuthorName = author.Username
+	}
+
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// event lama yang belum punya riwayat disimpan dulu sebagai revisi awal sebelum diubah
+func ensureBaselineRevision(eventID uint) error {
+	last, err := latestRevision(database.DB, eventID)
+	if err != nil || last != nil {
+		return err
+	}
+	_, err = recordRevision(database.DB, eventID, nil, "Revisi awal")
+	return err
+}
+
+// catat revisi setelah event diubah, penulisnya user yang sedang login
+func recordEventChange(c *gin.Context, eventID uint, note string) {
+	var author *models.User
+	if user, ok := currentUser(c); ok {
+		author = &user
+	}
+	if _, err := recordRevision(database.DB, eventID, author, note); err != nil {
+		log.Printf("Failed to record revision for event %d: %v", eventID, err)
+	}
+}
+
+func revisionResponse(revision models.EventRevision, withSnapshot bool) gin.H {
+	var changes []fieldChange
+	json.Unmarshal([]byte(revision.Changes), &changes)
+
+	response := gin.H{
+		"revision":    revision.Revision,
+		"author_id":   revision.AuthorID,
+		"author_name": revision.AuthorName,
+		"note":        revision.Note,
+		"created_at":  revision.CreatedAt,
+		"changes":     changes,
+	}
+
+	if withSnapshot {
+		var snapshot map[string]interface{}
+		json.Unmarshal([]byte(revision.Snapshot), &snapshot)
+		response["snapshot"] = snapshot
+	}
+	return response
+}
+
+// riwayat perubahan event, terbaru dulu
+func GetEventRevisions(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var revisions []models.EventRevision
+	if err := database.DB.Where("event_id = ?", event.ID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(revisions))
+	for _, revision := range revisions {
+		response = append(response, revisionResponse(revision, false))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": response})
+}
+
+func GetEventRevision(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var revision models.EventRevision
+	if err := database.DB.Where("event_id = ? AND revision = ?", event.ID, c.Param("revision")).First(&revision).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisionResponse(revision, true))
+}
+
+// kembalikan event dan sesinya ke isi revisi tertentu, dicatat sebagai revisi baru
+func RollbackEventRevision(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var revision models.EventRevision
+	if err := database.DB.Where("event_id = ? AND revision = ?", event.ID, c.Param("revision")).First(&revision).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	var restored models.Event
+	if err := json.Unmarshal([]byte(revision.Snapshot), &restored); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid revision snapshot"})
+		return
+	}
+	sessions := restored.Sessions
+
+	// data yang tidak disimpan di snapshot tetap memakai nilai sekarang
+	restored.ID = event.ID
+	restored.Sessions = nil
+	restored.AccessCode = event.AccessCode
+	restored.PopularityScore = event.PopularityScore
+	restored.DeletedAt = event.DeletedAt
+
+	// status publikasi, pemilik, visibilitas dan alasan status diatur lewat alurnya sendiri
+	// (review, transfer organizer, pembatalan), rollback tidak boleh mengubahnya
+	restored.PublicationStatus = event.PublicationStatus
+	restored.PublishAt = event.PublishAt
+	restored.OrganizerID = event.OrganizerID
+	restored.Visibility = event.Visibility
+	restored.StatusReason = event.StatusReason
+	restored.Version = event.Version + 1
+	restored.RemainingCapacity = event.RemainingCapacity + restored.Capacity - event.Capacity
+	if restored.RemainingCapacity < 0 {
+		restored.RemainingCapacity = 0
+	}
+	restored.Status = event.Status
+	if !models.IsManualStatus(restored.Status) {
+		restored.Status = ""
+		restored.Status = restored.ComputeStatus(time.Now())
+	}
+
+	for i := range sessions {
+		sessions[i].ID = 0
+		sessions[i].EventID = event.ID
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&restored).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		if len(sessions) > 0 {
+			if err := tx.Create(&sessions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back event"})
+		return
+	}
+
+	scheduler.Wake()
+	recordEventChange(c, event.ID, fmt.Sprintf("Rollback ke revisi %d", revision.Revision))
+
+	response, err := managedEventResponse(restored, sessions)
+	if err != nil {
+		log.Printf("Gagal memuat data pendamping event %d: %v", restored.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Event rolled back",
+		"event":    response,
+		"sessions": serializers.NewSessions(sessions),
+	})
+}
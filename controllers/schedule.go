@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// durasi default kalau jam selesai tidak diisi
+const defaultSlotDuration = 2 * time.Hour
+
+type scheduleSlot struct {
+	EventID   uint      `json:"event_id"`
+	EventName string    `json:"event_name"`
+	SessionID uint      `json:"session_id,omitempty"`
+	Date      string    `json:"date"`
+	Time      string    `json:"time,omitempty"`
+	Speaker   string    `json:"speaker,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// rentang waktu satu slot, tanpa jam dianggap sepanjang hari
+func slotRange(date time.Time, clock string) (time.Time, time.Time) {
+	startClock, endClock, ok := models.ParseClockRange(clock)
+	if !ok {
+		return date, date.AddDate(0, 0, 1)
+	}
+
+	start := models.AtClock(date, startClock)
+	if endClock > 0 {
+		return start, models.AtClock(date, endClock)
+	}
+	return start, start.Add(defaultSlotDuration)
+}
+
+// jadwal sebuah event: per sesi kalau ada, kalau tidak per hari dari awal sampai akhir event
+func eventSlots(event models.Event, sessions []models.Session) []scheduleSlot {
+	var slots []scheduleSlot
+
+	for _, session := range sessions {
+		clock := session.Time
+		if clock == "" {
+			clock = event.Time
+		}
+
+		var start, end time.Time
+		if session.StartsAt != nil {
+			start = *session.StartsAt
+			end = start.Add(defaultSlotDuration)
+			if session.EndsAt != nil {
+				end = *session.EndsAt
+			}
+		} else {
+			if session.Timezone == "" {
+				session.Timezone = event.Timezone
+			}
+			date, err := time.ParseInLocation(models.DateLayout, session.Date, session.TimeLocation())
+			if err != nil {
+				continue
+			}
+			start, end = slotRange(date, clock)
+		}
+
+		location := session.Location
+		if location == "" {
+			location = event.Location
+		}
+
+		slots = append(slots, scheduleSlot{
+			EventID:   event.ID,
+			EventName: event.Name,
+			SessionID: session.ID,
+			Date:      session.Date,
+			Time:      clock,
+			Speaker:   session.Speaker,
+			Location:  location,
+			Start:     start,
+			End:       end,
+		})
+	}
+
+	if len(slots) > 0 {
+		return slots
+	}
+
+	start, end, err := event.Period()
+	if err != nil {
+		return nil
+	}
+
+	for day := models.AtClock(start, 0); day.Before(end); day = day.AddDate(0, 0, 1) {
+		slotStart, slotEnd := slotRange(day, event.Time)
+		slots = append(slots, scheduleSlot{
+			EventID:   event.ID,
+			EventName: event.Name,
+			Date:      day.Format(models.DateLayout),
+			Time:      event.Time,
+			Location:  event.Location,
+			Start:     slotStart,
+			End:       slotEnd,
+		})
+	}
+
+	return slots
+}
+
+// ambil jadwal semua event yang didaftarkan user, sesi dimuat sekaligus
+func userScheduleSlots(userID uint, excludeEventID uint) ([]scheduleSlot, error) {
+	var registrations []models.Registration
+	if err := database.DB.Preload("Event").
+		Where("user_id = ? AND group_id IS NULL AND status <> ? AND event_id <> ?", userID, "rejected", excludeEventID).
+		Find(&registrations).Error; err != nil {
+		return nil, err
+	}
+
+	if len(registrations) == 0 {
+		return nil, nil
+	}
+
+	eventIDs := make([]uint, 0, len(registrations))
+	for _, registration := range registrations {
+		eventIDs = append(eventIDs, registration.EventID)
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("event_id IN ?", eventIDs).Order("date").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	sessionsByEvent := make(map[uint][]models.Session)
+	for _, session := range sessions {
+		sessionsByEvent[session.EventID] = append(sessionsByEvent[session.EventID], session)
+	}
+
+	var slots []scheduleSlot
+	for _, registration := range registrations {
+		slots = append(slots, eventSlots(registration.Event, sessionsByEvent[registration.EventID])...)
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Start.Before(slots[j].Start)
+	})
+
+	return slots, nil
+}
+
+// cari jadwal yang bentrok antara event baru dan event yang sudah didaftarkan user
+func findScheduleConflicts(userID uint, event models.Event) ([]gin.H, error) {
+	existing, err := userScheduleSlots(userID, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("event_id = ?", event.ID).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	var conflicts []gin.H
+	for _, slot := range eventSlots(event, sessions) {
+		for _, other := range existing {
+			if slot.Start.Before(other.End) && other.Start.Before(slot.End) {
+				conflicts = append(conflicts, gin.H{
+					"date":                slot.Date,
+					"time":                slot.Time,
+					"conflict_event_id":   other.EventID,
+					"conflict_event_name": other.EventName,
+					"conflict_date":       other.Date,
+					"conflict_time":       other.Time,
+				})
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+func blockScheduleConflicts() bool {
+	return strings.ToLower(os.Getenv("SCHEDULE_CONFLICT_MODE")) == "block"
+}
+
+// jadwal gabungan semua event yang didaftarkan user, urut waktu
+func GetMySchedule(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	loggedInUser := user.(models.User)
+
+	slots, err := userScheduleSlots(loggedInUser.ID, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedule"})
+		return
+	}
+
+	if slots == nil {
+		slots = []scheduleSlot{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"username": loggedInUser.Username,
+		"schedule": slots,
+	})
+}
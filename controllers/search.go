@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultSuggestLimit = 5
+	maxSuggestLimit     = 20
+)
+
+// tsquery gabungan konfigurasi Indonesia dan Inggris, argumennya: konfigurasi, q, q
+const searchTSQuery = "(websearch_to_tsquery(CAST(? AS regconfig), ?) || websearch_to_tsquery('english', ?))"
+
+const (
+	highlightAllOptions = "HighlightAll=true, StartSel=<mark>, StopSel=</mark>"
+	snippetOptions      = "StartSel=<mark>, StopSel=</mark>, MinWords=10, MaxWords=30, MaxFragments=2"
+)
+
+type searchResult struct {
+	ID                 uint       `json:"id"`
+	Name               string     `json:"name"`
+	StartsAt           *time.Time `json:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at"`
+	Timezone           string     `json:"timezone"`
+	Location           string     `json:"location"`
+	Photo              string     `json:"photo"`
+	Price              string     `json:"price"`
+	Status             string     `json:"status"`
+	Score              float64    `json:"score"`
+	NameHighlight      string     `json:"name_highlight"`
+	DescriptionSnippet string     `json:"description_snippet"`
+	SpeakersHighlight  string     `json:"speakers_highlight,omitempty"`
+}
+
+type searchSuggestion struct {
+	ID   uint   `json:"id,omitempty"`
+	Text string `json:"text"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// escape HTML teks sumber sebelum ts_headline, jadi markup di hasil cuplikan hanya <mark>
+// dan klien aman menampilkannya sebagai HTML
+func escapeHTMLSQL(expr string) string {
+	return "replace(replace(replace(replace(replace(" + expr +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+}
+
+func searchArgs(q string) []interface{} {
+	return []interface{}{database.SearchConfig, q, q}
+}
+
+// syarat cocok dan skor relevansi. dengan pg_trgm, nama event dan pembicara yang mirip
+// (salah ketik) ikut cocok
+func searchMatch(q string) (match string, matchArgs []interface{}, score string, scoreArgs []interface{}) {
+	match = "events.search_vector @@ " + searchTSQuery
+	matchArgs = searchArgs(q)
+	score = "ts_rank_cd(events.search_vector, " + searchTSQuery + ", 32)"
+	scoreArgs = searchArgs(q)
+
+	if database.Trigram {
+		match = "(" + match + ` OR ? <% events.name OR EXISTS (SELECT 1 FROM sessions
+			WHERE sessions.event_id = events.id AND sessions.deleted_at IS NULL AND ? <% sessions.speaker))`
+		matchArgs = append(matchArgs, q, q)
+		score += " + word_similarity(?, events.name) / 2"
+		scoreArgs = append(scoreArgs, q)
+	}
+	return match, matchArgs, score, scoreArgs
+}
+
+// pencarian full-text event publik di nama, deskripsi, benefit, pembicara sesi dan venue.
+// diurutkan dari yang paling relevan, filter daftar event (category, from, to, dst) juga berlaku
+func SearchEvents(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	match, matchArgs, score, scoreArgs := searchMatch(q)
+	query := database.DB.Model(&models.Event{}).
+		Where("events.visibility = ? AND events.publication_status = ?", "public", models.PublicationPublished).
+		Where(match, matchArgs...)
+	query, err := applyEventFilters(c, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page := pageFromQuery(c, defaultPerPage)
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search events"})
+		return
+	}
+
+	inner := query.Select(`events.id, events.name, events.description, events.starts_at, events.ends_at,
+		events.timezone, events.location, events.photo, events.price, events.status, (`+score+`) AS score`, scoreArgs...).
+		Order("score DESC, events.starts_at, events.id").
+		Offset((page.Page - 1) * page.PerPage).
+		Limit(page.PerPage + 1)
+
+	// cuplikan dengan <mark> hanya dibuat untuk baris di halaman ini
+	headline := "ts_headline(CAST(? AS regconfig), %s, " + searchTSQuery + ", ?)"
+	var headlineArgs []interface{}
+	for _, options := range []string{highlightAllOptions, snippetOptions, snippetOptions} {
+		headlineArgs = append(append(append(headlineArgs, database.SearchConfig), searchArgs(q)...), options)
+	}
+
+	var results []searchResult
+	err = database.DB.Table("(?) AS results", inner).
+		Select("results.*, "+
+			fmt.Sprintf(headline, escapeHTMLSQL("results.name"))+" AS name_highlight, "+
+			fmt.Sprintf(headline, escapeHTMLSQL("results.description"))+" AS description_snippet, "+
+			fmt.Sprintf(headline, escapeHTMLSQL(`COALESCE((SELECT string_agg(speaker, ', ') FROM sessions
+				WHERE sessions.event_id = results.id AND sessions.deleted_at IS NULL AND speaker <> ''), '')`))+" AS speakers_highlight",
+			headlineArgs...).
+		Order("score DESC, starts_at, id").
+		Scan(&results).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search events"})
+		return
+	}
+
+	page.HasMore = len(results) > page.PerPage
+	if page.HasMore {
+		results = results[:page.PerPage]
+	}
+
+	now := time.Now()
+	for i := range results {
+		event := models.Event{Status: results[i].Status, StartsAt: results[i].StartsAt, EndsAt: results[i].EndsAt, Timezone: results[i].Timezone}
+		results[i].Status = event.ComputeStatus(now)
+		results[i].Score = math.Round(results[i].Score*10000) / 10000
+		if !strings.Contains(results[i].SpeakersHighlight, "<mark>") {
+			results[i].SpeakersHighlight = ""
+		}
+	}
+	if results == nil {
+		results = []searchResult{}
+	}
+
+	writePageHeaders(c, page)
+	c.JSON(http.StatusOK, gin.H{"query": q, "data": results, "pagination": page.meta()})
+}
+
+// cocok di awal kata, atau mirip kalau pg_trgm ada. urutan: awalan dulu lalu yang paling mirip,
+// lalu diurutkan dengan then
+func suggestMatch(column, q, then string) (string, []interface{}, clause.OrderBy) {
+	match := column + " ILIKE ? OR " + column + " ILIKE ?"
+	args := []interface{}{q + "%", "% " + q + "%"}
+
+	order := clause.Expr{SQL: "CASE WHEN " + column + " ILIKE ? THEN 0 ELSE 1 END", Vars: []interface{}{q + "%"}}
+	if database.Trigram {
+		match += " OR ? <% " + column
+		args = append(args, q)
+		order = clause.Expr{SQL: order.SQL + ", word_similarity(?, " + column + ") DESC", Vars: append(order.Vars, q)}
+	}
+	order.SQL += ", " + then
+	return "(" + match + ")", args, clause.OrderBy{Expression: order}
+}
+
+// saran autocomplete untuk kotak pencarian: nama event, pembicara, tag, kategori dan venue
+func GetSearchSuggestions(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if value, err := strconv.Atoi(c.Query("limit")); err == nil && value > 0 {
+		limit = int(math.Min(float64(value), maxSuggestLimit))
+	}
+
+	events := []searchSuggestion{}
+	match, args, order := suggestMatch("name", q, "popularity_score DESC")
+	if err := database.DB.Model(&models.Event{}).Select("id, name AS text").
+		Where("visibility = ? AND publication_status = ?", "public", models.PublicationPublished).
+		Where("ends_at IS NULL OR ends_at >= ?", time.Now()).
+		Where(match, args...).
+		Order(order).
+		Limit(limit).Scan(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suggestions"})
+		return
+	}
+
+	speakers := []searchSuggestion{}
+	match, args, order = suggestMatch("sessions.speaker", q, "sessions.speaker")
+	database.DB.Table("sessions").Select("sessions.speaker AS text").
+		Joins("JOIN events ON events.id = sessions.event_id AND events.deleted_at IS NULL").
+		Where("sessions.deleted_at IS NULL AND sessions.speaker <> ''").
+		Where("events.visibility = ? AND events.publication_status = ?", "public", models.PublicationPublished).
+		Where(match, args...).
+		Group("sessions.speaker").
+		Order(order).Limit(limit).Scan(&speakers)
+
+	tags := []searchSuggestion{}
+	match, args, order = suggestMatch("name", q, "name")
+	database.DB.Model(&models.Tag{}).Select("id, name AS text, slug").
+		Where(match, args...).Order(order).Limit(limit).Scan(&tags)
+
+	categories := []searchSuggestion{}
+	database.DB.Model(&models.Category{}).Select("id, name AS text, slug").
+		Where(match, args...).Order(order).Limit(limit).Scan(&categories)
+
+	venues := []searchSuggestion{}
+	database.DB.Model(&models.Venue{}).Select("id, name AS text").
+		Where(match, args...).Order(order).Limit(limit).Scan(&venues)
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":      q,
+		"events":     events,
+		"speakers":   speakers,
+		"tags":       tags,
+		"categories": categories,
+		"venues":     venues,
+	})
+}
@@ -0,0 +1,581 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/scheduler"
+	"backend-event/serializers"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maksimum occurrence yang dibuat sekaligus untuk satu series
+const maxSeriesOccurrences = 200
+
+// jangka waktu pembuatan occurrence untuk series tanpa COUNT/UNTIL
+func seriesHorizon() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("SERIES_HORIZON_DAYS"))
+	if err != nil || days <= 0 {
+		days = 90
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func canManageSeries(user models.User, series models.EventSeries) bool {
+	return canManageEvent(user, models.Event{OrganizerID: series.OrganizerID})
+}
+
+func findManagedSeries(c *gin.Context) (models.EventSeries, bool) {
+	var series models.EventSeries
+
+	user, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return series, false
+	}
+
+	if err := database.DB.First(&series, c.Param("id")).Error; err != nil || !canManageSeries(user, series) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return series, false
+	}
+
+	return series, true
+}
+
+// buat occurrence baru dari template (occurrence pertama / terakhir) sampai horizon,
+// setiap occurrence punya kapasitas dan pendaftaran sendiri
+func generateOccurrences(tx *gorm.DB, series *models.EventSeries, template models.Event, sessions []models.Session) ([]models.Event, error) {
+	loc := template.TimeLocation()
+	rule, err := models.ParseRRule(series.RRule, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	templateStart, templateEnd, err := template.Period()
+	if err != nil {
+		return nil, err
+	}
+	duration := templateEnd.Sub(templateStart)
+
+	horizon := time.Now().Add(seriesHorizon())
+	if rule.Bounded() {
+		horizon = series.StartsAt.AddDate(10, 0, 0)
+	}
+
+	var created []models.Event
+	now := time.Now()
+	for _, start := range rule.Occurrences(series.StartsAt.In(loc), horizon, models.ExDates(series.ExDates)) {
+		if series.GeneratedUntil != nil && !start.After(*series.GeneratedUntil) {
+			continue
+		}
+		if len(created) >= maxSeriesOccurrences {
+			break
+		}
+
+		event := template
+		event.ID = 0
+		event.SeriesID = &series.ID
+		event.OccurrenceDate = start.Format(models.DateLayout)
+		event.IsException = false
+		event.Sessions = nil
+		event.PopularityScore = 0
+		event.RemainingCapacity = event.Capacity
+		if err := event.SetSchedule(start, start.Add(duration)); err != nil {
+			return nil, err
+		}
+		event.Status = ""
+		event.StatusReason = ""
+		event.Status = event.ComputeStatus(now)
+
+		if err := tx.Create(&event).Error; err != nil {
+			return nil, err
+		}
+
+		copies, err := copySessions(sessions, event.ID, start.Sub(templateStart))
+		if err != nil {
+			return nil, err
+		}
+		if len(copies) > 0 {
+			if err := tx.Create(&copies).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		created = append(created, event)
+		generatedUntil := start
+		series.GeneratedUntil = &generatedUntil
+	}
+
+	if err := tx.Model(series).Update("generated_until", series.GeneratedUntil).Error; err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// buat event berulang: form sama dengan CreateEvent ditambah rrule & exdates,
+// jadwal di form menjadi occurrence pertama
+func CreateSeries(c *gin.Context) {
+	template, sessions, ok := eventFromForm(c)
+	if !ok {
+		return
+	}
+
+	rrule := c.PostForm("rrule")
+	if _, err := models.ParseRRule(rrule, template.TimeLocation()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var exdates []string
+	for date := range models.ExDates(c.PostForm("exdates")) {
+		if _, err := time.Parse(models.DateLayout, date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exdates format"})
+			return
+		}
+		exdates = append(exdates, date)
+	}
+	sort.Strings(exdates)
+
+	start, _, err := template.Period()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series := models.EventSeries{
+		Name:        template.Name,
+		OrganizerID: template.OrganizerID,
+		RRule:       rrule,
+		ExDates:     strings.Join(exdates, ","),
+		Timezone:    template.Timezone,
+		StartsAt:    start,
+	}
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(&series).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create series"})
+		return
+	}
+
+	occurrences, err := generateOccurrences(tx, &series, template, sessions)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create occurrences", "details": err.Error()})
+		return
+	}
+
+	if len(occurrences) == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Recurrence rule does not produce any occurrence"})
+		return
+	}
+
+	tx.Commit()
+	scheduler.Wake()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Series created successfully",
+		"series":      serializers.NewSeries(series),
+		"occurrences": seriesOccurrences(occurrences),
+	})
+}
+
+func seriesOccurrences(events []models.Event) []serializers.Occurrence {
+	return serializers.NewOccurrences(events, time.Now())
+}
+
+func GetSeriesByID(c *gin.Context) {
+	var series models.EventSeries
+	if err := database.DB.First(&series, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	}
+
+	var events []models.Event
+	if err := database.DB.Where("series_id = ? AND publication_status = ?", series.ID, models.PublicationPublished).
+		Order("starts_at").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch occurrences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"series":      serializers.NewSeries(series),
+		"occurrences": seriesOccurrences(events),
+	})
+}
+
+// buat occurrence berikutnya untuk series tanpa batas akhir
+func ExtendSeries(c *gin.Context) {
+	series, ok := findManagedSeries(c)
+	if !ok {
+		return
+	}
+
+	// template diambil dari occurrence terakhir yang bukan pengecualian, jadi perubahan
+	// scope=this di satu occurrence tidak ikut tersalin, perubahan scope=future tetap ikut
+	var template models.Event
+	if err := database.DB.Preload("Tags").Preload("Categories").
+		Where("series_id = ? AND is_exception = ? AND status <> ?", series.ID, false, models.StatusCancelled).
+		Order("starts_at DESC").First(&template).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Series has no occurrence to extend from"})
+		return
+	}
+
+	var sessions []models.Session
+	database.DB.Where("event_id = ?", template.ID).Find(&sessions)
+
+	tx := database.DB.Begin()
+	occurrences, err := generateOccurrences(tx, &series, template, sessions)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create occurrences", "details": err.Error()})
+		return
+	}
+	tx.Commit()
+	scheduler.Wake()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Series extended",
+		"occurrences": seriesOccurrences(occurrences),
+	})
+}
+
+// occurrence yang terkena perubahan: satu saja atau occurrence ini dan sesudahnya
+func scopedOccurrences(c *gin.Context, series models.EventSeries) (models.Event, []models.Event, bool) {
+	var target models.Event
+	if err := database.DB.Where("id = ? AND series_id = ?", c.Param("event_id"), series.ID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Occurrence not found"})
+		return target, nil, false
+	}
+
+	switch c.DefaultQuery("scope", "this") {
+	case "this":
+		return target, []models.Event{target}, true
+	case "future":
+		var events []models.Event
+		if err := database.DB.Where("series_id = ? AND starts_at >= ?", series.ID, target.StartsAt).
+			Order("starts_at").Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch occurrences"})
+			return target, nil, false
+		}
+		return target, events, true
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope, must be this or future"})
+	return target, nil, false
+}
+
+// geser aturan series mulai dari occurrence from sejauh shift, supaya occurrence yang
+// dibuat ExtendSeries sesudahnya ikut jadwal baru. COUNT dikurangi occurrence sebelum from
+// karena hitungan dimulai lagi dari starts_at yang baru
+func rescheduleSeries(series *models.EventSeries, from time.Time, shift time.Duration, loc *time.Location) error {
+	rule, err := models.ParseRRule(series.RRule, loc)
+	if err != nil {
+		return err
+	}
+
+	from = from.In(loc)
+	newFrom := from.Add(shift)
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(time.Date(newFrom.Year(), newFrom.Month(), newFrom.Day(), 0, 0, 0, 0, time.UTC).Sub(fromDate).Hours() / 24)
+
+	if rule.Count > 0 {
+		rule.Count -= len(rule.Occurrences(series.StartsAt.In(loc), from.Add(-time.Second), nil))
+		if rule.Count < 1 {
+			rule.Count = 1
+		}
+	}
+	if rule.Until != nil {
+		until := rule.Until.Add(shift)
+		rule.Until = &until
+	}
+	if rule, err = rule.ShiftDays(days); err != nil {
+		return err
+	}
+
+	// tanggal yang dikecualikan mulai dari occurrence ini ikut bergeser
+	var exdates []string
+	for date := range models.ExDates(series.ExDates) {
+		if parsed, err := time.Parse(models.DateLayout, date); err == nil && !parsed.Before(fromDate) {
+			date = parsed.AddDate(0, 0, days).Format(models.DateLayout)
+		}
+		exdates = append(exdates, date)
+	}
+	sort.Strings(exdates)
+
+	if series.GeneratedUntil != nil && !series.GeneratedUntil.Before(from) {
+		generatedUntil := series.GeneratedUntil.Add(shift)
+		series.GeneratedUntil = &generatedUntil
+	}
+	series.RRule = rule.String()
+	series.ExDates = strings.Join(exdates, ",")
+	series.StartsAt = newFrom
+	return nil
+}
+
+// ubah satu occurrence (scope=this) atau semua occurrence mulai dari occurrence ini (scope=future)
+func UpdateSeriesOccurrence(c *gin.Context) {
+	series, ok := findManagedSeries(c)
+	if !ok {
+		return
+	}
+
+	target, events, ok := scopedOccurrences(c, series)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Benefits    *string `json:"benefits"`
+		Price       *string `json:"price"`
+		Link        *string `json:"link"`
+		Address     *string `json:"address"`
+		Capacity    *int    `json:"capacity"`
+		StartsAt    string  `json:"starts_at"`
+		EndsAt      string  `json:"ends_at"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	// perubahan jam pada occurrence target diterapkan sebagai pergeseran ke occurrence lain
+	targetStart, targetEnd, err := target.Period()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shift := time.Duration(0)
+	duration := targetEnd.Sub(targetStart)
+	if input.StartsAt != "" {
+		loc := target.TimeLocation()
+		start, err := models.ParseTimestamp(input.StartsAt, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidStartDate.Error()})
+			return
+		}
+		end := start.Add(duration)
+		if input.EndsAt != "" {
+			if end, err = models.ParseTimestamp(input.EndsAt, loc); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidEndDate.Error()})
+				return
+			}
+		}
+		if end.Before(start) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrEndBeforeStart.Error()})
+			return
+		}
+		shift = start.Sub(targetStart)
+		duration = end.Sub(start)
+	}
+
+	for _, event := range events {
+		if err := ensureBaselineRevision(event.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record event history"})
+			return
+		}
+	}
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	for i := range events {
+		event := &events[i]
+
+		if input.Name != nil {
+			event.Name = *input.Name
+		}
+		if input.Description != nil {
+			event.Description = *input.Description
+		}
+		if input.Benefits != nil {
+			event.Benefits = *input.Benefits
+		}
+		if input.Price != nil {
+			event.Price = *input.Price
+		}
+		if input.Link != nil {
+			event.Link = *input.Link
+		}
+		if input.Address != nil {
+			event.Address = *input.Address
+		}
+		if input.Capacity != nil {
+			event.RemainingCapacity += *input.Capacity - event.Capacity
+			if event.RemainingCapacity < 0 {
+				event.RemainingCapacity = 0
+			}
+			event.Capacity = *input.Capacity
+		}
+
+		if input.StartsAt != "" {
+			start, _, err := event.Period()
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			start = start.Add(shift)
+			if err := event.SetSchedule(start, start.Add(duration)); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if !models.IsManualStatus(event.Status) {
+				event.Status = ""
+			}
+			event.Status = event.ComputeStatus(now)
+
+			if shift != 0 {
+				if err := shiftSessions(tx, event.ID, shift); err != nil {
+					tx.Rollback()
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule sessions"})
+					return
+				}
+			}
+		}
+
+		if c.DefaultQuery("scope", "this") == "this" {
+			event.IsException = true
+		}
+		event.Version++
+
+		if err := tx.Save(event).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update occurrence"})
+			return
+		}
+	}
+
+	if c.DefaultQuery("scope", "this") == "future" && shift != 0 {
+		if err := rescheduleSeries(&series, targetStart, shift, target.TimeLocation()); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot reschedule the series rule", "details": err.Error()})
+			return
+		}
+		if err := tx.Model(&series).Updates(map[string]interface{}{
+			"rrule":           series.RRule,
+			"exdates":         series.ExDates,
+			"starts_at":       series.StartsAt,
+			"generated_until": series.GeneratedUntil,
+		}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update series"})
+			return
+		}
+	}
+
+	tx.Commit()
+	scheduler.Wake()
+
+	for _, event := range events {
+		recordEventChange(c, event.ID, "")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Occurrences updated successfully",
+		"occurrences": seriesOccurrences(events),
+	})
+}
+
+// batalkan satu occurrence (scope=this) atau semua occurrence mulai dari occurrence ini (scope=future).
+// occurrence yang sudah punya pendaftar ditandai cancelled dan pendaftarnya dikabari, sisanya dihapus
+func CancelSeriesOccurrence(c *gin.Context) {
+	series, ok := findManagedSeries(c)
+	if !ok {
+		return
+	}
+
+	target, events, ok := scopedOccurrences(c, series)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&input)
+
+	if c.DefaultQuery("scope", "this") == "this" {
+		exdates := models.ExDates(series.ExDates)
+		exdates[target.OccurrenceDate] = true
+
+		var dates []string
+		for date := range exdates {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+		series.ExDates = strings.Join(dates, ",")
+	} else {
+		targetStart, _, err := target.Period()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		series.RRule = models.WithUntil(series.RRule, targetStart.Add(-time.Second))
+	}
+
+	if err := database.DB.Model(&series).Updates(map[string]interface{}{
+		"exdates": series.ExDates,
+		"rrule":   series.RRule,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update series"})
+		return
+	}
+
+	var cancelled, deleted int
+	for _, event := range events {
+		var registrations int64
+		database.DB.Model(&models.Registration{}).Where("event_id = ?", event.ID).Count(&registrations)
+
+		if registrations == 0 {
+			database.DB.Where("event_id = ?", event.ID).Delete(&models.Session{})
+			database.DB.Delete(&event)
+			deleted++
+			continue
+		}
+
+		oldDate := formatEventDate(event)
+		if err := database.DB.Model(&event).Updates(map[string]interface{}{
+			"status":        models.StatusCancelled,
+			"status_reason": input.Reason,
+			"version":       gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			continue
+		}
+		notifyRegistrants(event, models.StatusCancelled, input.Reason, oldDate)
+		cancelled++
+	}
+
+	scheduler.Wake()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Occurrences cancelled",
+		"cancelled": cancelled,
+		"deleted":   deleted,
+		"series":    serializers.NewSeries(series),
+	})
+}
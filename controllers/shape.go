@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"backend-event/serializers"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// relasi default kalau ?include= tidak diisi
+var (
+	eventListInclude       = []string{"location", "category", "categories", "tags", "rating_summary"}
+	eventDetailInclude     = []string{"location", "category", "categories", "tags", "rating_summary", "sessions", "venue"}
+	managedListInclude     = []string{"location", "category", "categories", "tags", "rating_summary", "sessions"}
+	registeredEventInclude = []string{"location", "category", "categories", "tags", "rating_summary", "registration"}
+)
+
+// bentuk response yang diminta klien: ?fields= membatasi key, ?include= memilih relasi.
+// relasi yang tidak diminta juga tidak di-query
+type responseShape struct {
+	fields    []string
+	include   map[string]bool
+	relations map[string][]string
+}
+
+// semua relasi, tanpa batasan field (response organizer)
+func fullShape(relations map[string][]string) responseShape {
+	shape := responseShape{include: make(map[string]bool), relations: relations}
+	for name := range relations {
+		shape.include[name] = true
+	}
+	return shape
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// tanpa ?include= dipakai relasi default endpoint, ?include= kosong berarti tanpa relasi
+func parseResponseShape(c *gin.Context, relations map[string][]string, defaults ...string) (responseShape, bool) {
+	shape := responseShape{fields: splitList(c.Query("fields")), include: make(map[string]bool), relations: relations}
+
+	names := defaults
+	if value, ok := c.GetQuery("include"); ok {
+		names = splitList(value)
+	}
+	for _, name := range names {
+		if _, ok := relations[name]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown include: %s", name)})
+			return shape, false
+		}
+		shape.include[name] = true
+	}
+	return shape, true
+}
+
+// relasi perlu dimuat kalau diminta dan salah satu key-nya masuk ?fields=
+func (s responseShape) needs(relation string) bool {
+	if !s.include[relation] {
+		return false
+	}
+	if len(s.fields) == 0 {
+		return true
+	}
+	for _, key := range s.relations[relation] {
+		for _, field := range s.fields {
+			if field == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preload tag / kategori event hanya kalau dibutuhkan. prefix untuk relasi bersarang ("Event.")
+func (s responseShape) preload(query *gorm.DB, prefix string) *gorm.DB {
+	if s.needs("tags") {
+		query = query.Preload(prefix + "Tags")
+	}
+	if s.needs("categories") {
+		query = query.Preload(prefix + "Categories")
+	}
+	return query
+}
+
+func (s responseShape) render(v interface{}) (interface{}, error) {
+	var omit []string
+	for name, keys := range s.relations {
+		if !s.include[name] {
+			omit = append(omit, keys...)
+		}
+	}
+	return serializers.Project(v, s.fields, omit)
+}
+
+// tulis response sesuai bentuk yang diminta
+func (s responseShape) respond(c *gin.Context, status int, v interface{}) {
+	body, err := s.render(v)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build response"})
+		return
+	}
+	c.JSON(status, body)
+}
@@ -0,0 +1,448 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/scheduler"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gomail "gopkg.in/mail.v2"
+	"gorm.io/gorm"
+)
+
+// RefundHook dipanggil untuk setiap pendaftaran perorangan berbayar saat event dibatalkan.
+// Kalau nil, refund ditandai pending untuk diproses manual.
+var RefundHook func(registration models.Registration, event models.Event) error
+
+// GroupRefundHook dipanggil sekali per pendaftaran grup berbayar, memakai pembayaran grup.
+var GroupRefundHook func(group models.RegistrationGroup, event models.Event) error
+
+type statusInput struct {
+	Status   string `json:"status"`
+	Reason   string `json:"reason"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+}
+
+// ubah status event, simpan riwayat lalu kabari semua pendaftar
+func changeEventStatus(c *gin.Context, event models.Event, input statusInput) {
+	user, _ := currentUser(c)
+	now := time.Now()
+
+	statusLog := models.EventStatusLog{
+		EventID:     event.ID,
+		FromStatus:  event.ComputeStatus(now),
+		Reason:      input.Reason,
+		OldStartsAt: event.StartsAt,
+		OldEndsAt:   event.EndsAt,
+		ChangedBy:   user.ID,
+	}
+	oldDate := formatEventDate(event)
+
+	var shift time.Duration
+	if input.StartsAt != "" {
+		loc := event.TimeLocation()
+		start, err := models.ParseTimestamp(input.StartsAt, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidStartDate.Error()})
+			return
+		}
+
+		oldStart, oldEnd, err := event.Period()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		end := start.Add(oldEnd.Sub(oldStart))
+		if input.EndsAt != "" {
+			if end, err = models.ParseTimestamp(input.EndsAt, loc); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidEndDate.Error()})
+				return
+			}
+		}
+
+		if err := event.SetSchedule(start, end); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		shift = start.Sub(oldStart)
+		statusLog.NewStartsAt = event.StartsAt
+		statusLog.NewEndsAt = event.EndsAt
+	}
+
+	switch input.Status {
+	case models.StatusCancelled:
+		event.Status = models.StatusCancelled
+		event.StatusReason = input.Reason
+	case models.StatusPostponed:
+		// tanpa tanggal baru event ditunda sampai waktu yang belum ditentukan,
+		// dengan tanggal baru status kembali mengikuti jadwal
+		event.Status = models.StatusPostponed
+		if statusLog.NewStartsAt != nil {
+			event.Status = ""
+			event.Status = event.ComputeStatus(now)
+		}
+		event.StatusReason = input.Reason
+	case "scheduled":
+		event.Status = ""
+		event.StatusReason = ""
+		event.Status = event.ComputeStatus(now)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, must be cancelled, postponed or scheduled"})
+		return
+	}
+	statusLog.ToStatus = input.Status
+
+	if err := ensureBaselineRevision(event.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record event history"})
+		return
+	}
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Model(&event).Updates(map[string]interface{}{
+		"status":        event.Status,
+		"status_reason": event.StatusReason,
+		"starts_at":     event.StartsAt,
+		"ends_at":       event.EndsAt,
+		"date_start":    event.DateStart,
+		"date_end":      event.DateEnd,
+		"time":          event.Time,
+		"version":       gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event status"})
+		return
+	}
+
+	if shift != 0 {
+		if err := shiftSessions(tx, event.ID, shift); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule sessions"})
+			return
+		}
+	}
+
+	if err := tx.Create(&statusLog).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record status history"})
+		return
+	}
+
+	tx.Commit()
+	scheduler.Wake()
+	recordEventChange(c, event.ID, input.Reason)
+
+	var notified, refunds int
+	if input.Status != "scheduled" || shift != 0 {
+		notified, refunds = notifyRegistrants(event, input.Status, input.Reason, oldDate)
+		database.DB.Model(&statusLog).Update("notified_count", notified)
+		statusLog.NotifiedCount = notified
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Event status updated",
+		"status":            event.Status,
+		"status_reason":     event.StatusReason,
+		"starts_at":         event.StartsAt,
+		"ends_at":           event.EndsAt,
+		"notified":          notified,
+		"refunds_requested": refunds,
+		"history":           statusLog,
+	})
+}
+
+// geser jadwal semua sesi sejauh perubahan jadwal event
+func shiftSessions(tx *gorm.DB, eventID uint, shift time.Duration) error {
+	var sessions []models.Session
+	if err := tx.Where("event_id = ?", eventID).Find(&sessions).Error; err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.StartsAt == nil {
+			if err := session.ScheduleFromLegacy(); err != nil {
+				continue
+			}
+		}
+
+		var end time.Time
+		if session.EndsAt != nil {
+			end = session.EndsAt.Add(shift)
+		}
+		if err := session.SetSchedule(session.StartsAt.Add(shift), end); err != nil {
+			return err
+		}
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refund satu pendaftaran perorangan
+func refundRegistration(registration models.Registration, event models.Event) {
+	refundStatus := "requested"
+	if RefundHook == nil {
+		refundStatus = "pending"
+	} else if err := RefundHook(registration, event); err != nil {
+		log.Printf("Refund failed for registration %d: %v", registration.ID, err)
+		refundStatus = "failed"
+	}
+	database.DB.Model(&registration).Update("refund_status", refundStatus)
+}
+
+// refund grup sekali untuk semua kursinya, status refund ikut ditulis ke kursi yang disetujui.
+// false kalau grup tidak ditemukan, tidak ada pembayaran atau sudah pernah di-refund
+func refundGroup(groupID uint, event models.Event) bool {
+	var group models.RegistrationGroup
+	if err := database.DB.First(&group, groupID).Error; err != nil {
+		log.Printf("Failed to load registration group %d for refund: %v", groupID, err)
+		return false
+	}
+	if group.PaymentMethod == "" || group.RefundStatus != "" {
+		return false
+	}
+
+	refundStatus := "requested"
+	if GroupRefundHook == nil {
+		refundStatus = "pending"
+	} else if err := GroupRefundHook(group, event); err != nil {
+		log.Printf("Refund failed for registration group %d: %v", group.ID, err)
+		refundStatus = "failed"
+	}
+	database.DB.Model(&group).Update("refund_status", refundStatus)
+	database.DB.Model(&models.Registration{}).Where("group_id = ? AND status = ?", group.ID, "approved").Update("refund_status", refundStatus)
+	return true
+}
+
+// kirim email ke semua pendaftar aktif. kalau event batal, pendaftaran berbayar yang sudah
+// disetujui di-refund: perorangan per pendaftaran, grup sekali per grup. yang refund_status-nya
+// sudah terisi (pembatalan sebelumnya) tidak di-refund lagi
+func notifyRegistrants(event models.Event, status, reason, oldDate string) (int, int) {
+	var registrations []models.Registration
+	if err := database.DB.Where("event_id = ? AND status IN ?", event.ID, []string{"approved", "pending"}).Find(&registrations).Error; err != nil {
+		log.Printf("Failed to load registrants for event %d: %v", event.ID, err)
+		return 0, 0
+	}
+
+	paid := strings.ToLower(event.Price) != "free"
+	refundedGroups := make(map[uint]bool)
+
+	var notified, refunds int
+	for _, registration := range registrations {
+		refund := false
+		if status == models.StatusCancelled && paid && registration.Status == "approved" {
+			if registration.GroupID == nil {
+				if registration.PaymentMethod != "" && registration.RefundStatus == "" {
+					refundRegistration(registration, event)
+					refund = true
+					refunds++
+				}
+			} else {
+				refunded, done := refundedGroups[*registration.GroupID]
+				if !done {
+					refunded = refundGroup(*registration.GroupID, event)
+					refundedGroups[*registration.GroupID] = refunded
+					if refunded {
+						refunds++
+					}
+				}
+				refund = refunded
+			}
+		}
+
+		if registration.Email == "" {
+			continue
+		}
+		if err := sendEventStatusEmail(registration.Email, registration.Name, event, status, reason, oldDate, refund); err != nil {
+			log.Printf("Failed to send status email to %s: %v", registration.Email, err)
+			continue
+		}
+		notified++
+	}
+
+	return notified, refunds
+}
+
+// set status manual event: cancelled / postponed, atau scheduled untuk kembali ke status otomatis
+func SetEventStatus(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var input statusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.Status == models.StatusCancelled && event.Status == models.StatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is already cancelled"})
+		return
+	}
+
+	changeEventStatus(c, event, input)
+}
+
+// batalkan event
+func CancelEvent(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	if event.Status == models.StatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event is already cancelled"})
+		return
+	}
+
+	var input statusInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is required"})
+		return
+	}
+	input.Status = models.StatusCancelled
+	input.StartsAt = ""
+
+	changeEventStatus(c, event, input)
+}
+
+// tunda event, opsional dengan tanggal baru
+func PostponeEvent(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	if event.Status == models.StatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cancelled events cannot be postponed"})
+		return
+	}
+
+	var input statusInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is required"})
+		return
+	}
+	input.Status = models.StatusPostponed
+
+	changeEventStatus(c, event, input)
+}
+
+// riwayat perubahan status event
+func GetEventStatusHistory(c *gin.Context) {
+	event, _, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var logs []models.EventStatusLog
+	if err := database.DB.Where("event_id = ?", event.ID).Order("created_at DESC").Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": logs})
+}
+
+func sendEventStatusEmail(to, name string, event models.Event, status, reason, oldDate string, refund bool) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", os.Getenv("SMTP_USER"))
+	m.SetHeader("To", to)
+
+	var subject, message, color string
+	switch status {
+	case models.StatusCancelled:
+		subject = "Event Dibatalkan - " + event.Name
+		message = "Mohon maaf, event berikut dibatalkan oleh penyelenggara."
+		color = "#dc3545"
+	case models.StatusPostponed:
+		subject = "Event Ditunda - " + event.Name
+		message = "Event berikut ditunda oleh penyelenggara. Tiket Anda tetap berlaku untuk jadwal yang baru."
+		color = "#856404"
+	default:
+		subject = "Perubahan Jadwal Event - " + event.Name
+		message = "Jadwal event berikut telah diperbarui oleh penyelenggara. Tiket Anda tetap berlaku."
+		color = "#007bff"
+	}
+	m.SetHeader("Subject", subject)
+
+	newDate := formatEventDate(event)
+	if status == models.StatusPostponed && event.Status == models.StatusPostponed {
+		newDate = "Akan diumumkan kemudian"
+	}
+
+	var scheduleTemplate string
+	if status != models.StatusCancelled {
+		scheduleTemplate = fmt.Sprintf(`
+				<p style="color: #666;"><strong>Jadwal Lama:</strong> %s</p>
+				<p style="color: #666;"><strong>Jadwal Baru:</strong> %s</p>
+		`, oldDate, newDate)
+	} else {
+		scheduleTemplate = fmt.Sprintf(`
+				<p style="color: #666;"><strong>Tanggal:</strong> %s</p>
+		`, oldDate)
+	}
+
+	var reasonTemplate string
+	if reason != "" {
+		reasonTemplate = fmt.Sprintf(`
+			<div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 15px 0;">
+				<p style="color: #666; margin: 0;"><strong>Alasan:</strong> %s</p>
+			</div>
+		`, reason)
+	}
+
+	var refundTemplate string
+	if refund {
+		refundTemplate = `
+			<p style="color: #28a745;">Pembayaran Anda akan dikembalikan sesuai metode pembayaran yang digunakan saat mendaftar.</p>
+		`
+	}
+
+	body := fmt.Sprintf(`
+		<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto;">
+			<h2 style="color: #333;">Halo, %s!</h2>
+			<p style="color: %s;">%s</p>
+
+			<div style="background-color: #f8f9fa; padding: 20px; border-radius: 5px; margin: 15px 0;">
+				<h3 style="color: #007bff; margin-top: 0;">%s</h3>
+				%s
+				<p style="color: #666;"><strong>Lokasi:</strong> %s</p>
+			</div>
+
+			%s
+			%s
+
+			<div style="margin-top: 20px; padding-top: 20px; border-top: 1px solid #eee;">
+				<p style="color: #666; font-size: 14px;">
+					Jika Anda memiliki pertanyaan, silakan hubungi tim support kami di:<br>
+					Email: anjarriho081@gmail.com<br>
+					WhatsApp: +62 890 3333 4444
+				</p>
+			</div>
+		</div>
+	`, name, color, message, event.Name, scheduleTemplate, event.Location, reasonTemplate, refundTemplate)
+
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer("smtp.gmail.com", 587, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"))
+	if err := d.DialAndSend(m); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type tagWithCount struct {
+	models.Tag
+	EventCount int64 `json:"event_count"`
+}
+
+// cari tag berdasarkan slug, tag baru dibuat sebagai tag bebas (belum dikurasi)
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := models.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		tag := models.Tag{Name: name, Slug: slug}
+		if err := tx.Where("slug = ?", slug).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// tag dari field form "tags" (dipisah koma). ok bernilai false kalau field tidak dikirim
+func tagsFromForm(c *gin.Context) ([]models.Tag, bool, error) {
+	value, ok := c.GetPostForm("tags")
+	if !ok {
+		return nil, false, nil
+	}
+	tags, err := findOrCreateTags(database.DB, strings.Split(value, ","))
+	return tags, true, err
+}
+
+// kategori event: kategori utama ditambah kategori dari field form "category_ids" (dipisah koma).
+// kalau category_ids tidak dikirim, kategori tambahan yang sudah ada tetap dipakai
+func categoriesFromForm(c *gin.Context, categoryID uint, current []models.Category, oldCategoryID uint) ([]models.Category, error) {
+	var ids []uint
+	if categoryID != 0 {
+		ids = append(ids, categoryID)
+	}
+
+	if value, ok := c.GetPostForm("category_ids"); ok {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, errors.New("Invalid category ID")
+			}
+			ids = append(ids, uint(id))
+		}
+	} else {
+		for _, category := range current {
+			if category.ID != oldCategoryID {
+				ids = append(ids, category.ID)
+			}
+		}
+	}
+
+	categories := []models.Category{}
+	if len(ids) == 0 {
+		return categories, nil
+	}
+
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	if err := database.DB.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	if len(categories) != len(unique) {
+		return nil, errors.New("Invalid category ID")
+	}
+	return categories, nil
+}
+
+// filter ?tag=slug1,slug2 dan ?category=id untuk daftar event
+func filterEventsByTaxonomy(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if value := c.Query("tag"); value != "" {
+		var slugs []string
+		for _, slug := range strings.Split(value, ",") {
+			if slug = models.Slugify(slug); slug != "" {
+				slugs = append(slugs, slug)
+			}
+		}
+		query = query.Where("events.id IN (?)", database.DB.Table("event_tags").
+			Select("event_tags.event_id").
+			Joins("JOIN tags ON tags.id = event_tags.tag_id").
+			Where("tags.slug IN ?", slugs))
+	}
+
+	// ?category=<id atau slug> ikut mencakup event di kategori turunannya
+	if value := c.Query("category"); value != "" {
+		ids := []uint{0}
+		if category, err := findCategory(value); err == nil {
+			categories, _ := loadCategories()
+			ids = categoryDescendantIDs(categories, category.ID)
+		}
+		query = query.Where("events.category_id IN ? OR events.id IN (?)", ids, database.DB.Table("event_categories").
+			Select("event_id").
+			Where("category_id IN ?", ids))
+	}
+	return query
+}
+
+func tagsWithCount() *gorm.DB {
+	return database.DB.Model(&models.Tag{}).
+		Select("tags.*, COUNT(event_tags.event_id) AS event_count").
+		Joins("LEFT JOIN event_tags ON event_tags.tag_id = tags.id").
+		Group("tags.id")
+}
+
+// tag baru dari admin langsung dianggap tag kurasi
+func CreateTag(c *gin.Context) {
+	var input struct {
+		Name    string `json:"name"`
+		Curated *bool  `json:"curated"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || models.Slugify(input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	tag := models.Tag{Name: strings.TrimSpace(input.Name), Slug: models.Slugify(input.Name), Curated: true}
+	if input.Curated != nil {
+		tag.Curated = *input.Curated
+	}
+
+	var existing models.Tag
+	if err := database.DB.Where("slug = ?", tag.Slug).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists", "data": existing})
+		return
+	}
+
+	if err := database.DB.Create(&tag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tag created", "data": tag})
+}
+
+// daftar tag beserta jumlah eventnya, bisa difilter ?curated=true dan ?q=
+func GetTags(c *gin.Context) {
+	query := tagsWithCount()
+	if curated, err := strconv.ParseBool(c.Query("curated")); err == nil {
+		query = query.Where("tags.curated = ?", curated)
+	}
+	if q := c.Query("q"); q != "" {
+		query = query.Where("tags.name ILIKE ? OR tags.slug LIKE ?", "%"+q+"%", "%"+models.Slugify(q)+"%")
+	}
+
+	tags := []tagWithCount{}
+	if err := query.Order("event_count DESC, tags.name").Scan(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// tag berdasarkan id atau slug
+func GetTagByID(c *gin.Context) {
+	query := tagsWithCount()
+	if id, err := strconv.Atoi(c.Param("id")); err == nil {
+		query = query.Where("tags.id = ?", id)
+	} else {
+		query = query.Where("tags.slug = ?", c.Param("id"))
+	}
+
+	var tag tagWithCount
+	if err := query.Take(&tag).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+func UpdateTag(c *gin.Context) {
+	var tag models.Tag
+	if err := database.DB.First(&tag, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	var input struct {
+		Name    string `json:"name"`
+		Curated *bool  `json:"curated"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.Name != "" {
+		slug := models.Slugify(input.Name)
+		if slug == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid name"})
+			return
+		}
+		var existing models.Tag
+		if err := database.DB.Where("slug = ? AND id <> ?", slug, tag.ID).First(&existing).Error; err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Another tag already uses this name, merge them instead", "data": existing})
+			return
+		}
+		tag.Name = strings.TrimSpace(input.Name)
+		tag.Slug = slug
+	}
+	if input.Curated != nil {
+		tag.Curated = *input.Curated
+	}
+
+	if err := database.DB.Save(&tag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag updated", "data": tag})
+}
+
+func DeleteTag(c *gin.Context) {
+	var tag models.Tag
+	if err := database.DB.First(&tag, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM event_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted"})
+}
+
+// gabungkan tag ke tag lain: event dipindah ke tag tujuan lalu tag asal dihapus
+func MergeTags(c *gin.Context) {
+	var source models.Tag
+	if err := database.DB.First(&source, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	var input struct {
+		Into uint `json:"into"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Into == 0 || input.Into == source.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "into must be another tag ID"})
+		return
+	}
+
+	var target models.Tag
+	if err := database.DB.First(&target, input.Into).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target tag not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO event_tags (event_id, tag_id)
+			SELECT event_id, ? FROM event_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM event_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tags merged", "data": target})
+}
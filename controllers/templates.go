@@ -0,0 +1,375 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"backend-event/scheduler"
+	"backend-event/serializers"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type newScheduleInput struct {
+	Name      string `json:"name"`
+	StartsAt  string `json:"starts_at"`
+	EndsAt    string `json:"ends_at"`
+	ShiftDays int    `json:"shift_days"`
+}
+
+// jadwal baru dari input: starts_at/ends_at, atau geser shift_days dari jadwal lama.
+// tanpa ends_at durasi lama dipertahankan
+func (input newScheduleInput) schedule(loc *time.Location, oldStart time.Time, duration time.Duration) (time.Time, time.Time, error) {
+	if input.StartsAt == "" {
+		start := oldStart.AddDate(0, 0, input.ShiftDays)
+		return start, start.Add(duration), nil
+	}
+
+	start, err := models.ParseTimestamp(input.StartsAt, loc)
+	if err != nil {
+		return start, start, models.ErrInvalidStartDate
+	}
+
+	end := start.Add(duration)
+	if input.EndsAt != "" {
+		if end, err = models.ParseTimestamp(input.EndsAt, loc); err != nil {
+			return start, end, models.ErrInvalidEndDate
+		}
+	}
+	return start, end, nil
+}
+
+// simpan event draft baru beserta sesinya
+func createDraft(c *gin.Context, event models.Event, sessions []models.Session, message string) {
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(&event).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event", "details": err.Error()})
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].EventID = event.ID
+	}
+
+	if len(sessions) > 0 {
+		if err := tx.Create(&sessions).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create sessions", "details": err.Error()})
+			return
+		}
+	}
+
+	tx.Commit()
+	scheduler.Wake()
+
+	response, err := managedEventResponse(event, sessions)
+	if err != nil {
+		log.Printf("Gagal memuat data pendamping event %d: %v", event.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  message,
+		"event":    response,
+		"sessions": serializers.NewSessions(sessions),
+	})
+}
+
+// reset data yang tidak ikut tersalin ke event baru
+func asNewDraft(event *models.Event, user models.User) {
+	event.ID = 0
+	event.OrganizerID = &user.ID
+	event.PublicationStatus = models.PublicationDraft
+	event.PublishAt = nil
+	event.ReviewNote = ""
+	event.Status = ""
+	event.StatusReason = ""
+	event.SeriesID = nil
+	event.OccurrenceDate = ""
+	event.IsException = false
+	event.PopularityScore = 0
+	event.RemainingCapacity = event.Capacity
+	event.Sessions = nil
+}
+
+// salin event (termasuk sesi, pengaturan tiket, benefit dan foto) menjadi draft baru dengan jadwal baru
+func DuplicateEvent(c *gin.Context) {
+	source, user, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	// tag dan kategori ikut tersalin ke draft baru
+	database.DB.Model(&source).Association("Tags").Find(&source.Tags)
+	database.DB.Model(&source).Association("Categories").Find(&source.Categories)
+
+	var input newScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil || (input.StartsAt == "" && input.ShiftDays == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "starts_at or shift_days is required"})
+		return
+	}
+
+	oldStart, oldEnd, err := source.Period()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start, end, err := input.schedule(source.TimeLocation(), oldStart, oldEnd.Sub(oldStart))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("event_id = ?", source.ID).Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	event := source
+	asNewDraft(&event, user)
+	if input.Name != "" {
+		event.Name = input.Name
+	}
+	if err := event.SetSchedule(start, end); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	event.Status = event.ComputeStatus(time.Now())
+
+	// batas transfer ikut bergeser sejauh jumlah hari pergeseran event
+	if event.TransferDeadline != "" {
+		if deadline, err := time.Parse(models.DateLayout, event.TransferDeadline); err == nil {
+			days := int(math.Round(models.AtClock(start, 0).Sub(models.AtClock(oldStart, 0)).Hours() / 24))
+			event.TransferDeadline = deadline.AddDate(0, 0, days).Format(models.DateLayout)
+		}
+	}
+
+	sessions, err = copySessions(sessions, 0, start.Sub(oldStart))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createDraft(c, event, sessions, "Event duplicated as draft")
+}
+
+// simpan event sebagai template yang bisa dipakai ulang
+func SaveEventAsTemplate(c *gin.Context) {
+	event, user, ok := findManagedEvent(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		TemplateName string `json:"template_name"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.TemplateName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template name is required"})
+		return
+	}
+
+	start, end, err := event.Period()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := models.EventTemplate{
+		TemplateName:     input.TemplateName,
+		OrganizerID:      &user.ID,
+		Name:             event.Name,
+		Description:      event.Description,
+		Timezone:         event.Timezone,
+		DurationMinutes:  int(end.Sub(start).Minutes()),
+		LocationID:       event.LocationID,
+		Location:         event.Location,
+		Address:          event.Address,
+		VenueID:          event.VenueID,
+		Capacity:         event.Capacity,
+		Photo:            event.Photo,
+		Price:            event.Price,
+		CategoryID:       event.CategoryID,
+		Benefits:         event.Benefits,
+		Mode:             event.Mode,
+		Link:             event.Link,
+		RequiresApproval: event.RequiresApproval,
+		Visibility:       event.Visibility,
+		TransferPolicy:   event.TransferPolicy,
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("event_id = ?", event.ID).Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	for _, session := range sessions {
+		if session.StartsAt == nil {
+			if err := session.ScheduleFromLegacy(); err != nil {
+				continue
+			}
+		}
+
+		templateSession := models.EventTemplateSession{
+			OffsetMinutes: int(session.StartsAt.Sub(start).Minutes()),
+			Timezone:      session.Timezone,
+			Speaker:       session.Speaker,
+			Location:      session.Location,
+		}
+		if session.EndsAt != nil {
+			templateSession.DurationMinutes = int(session.EndsAt.Sub(*session.StartsAt).Minutes())
+		}
+		template.Sessions = append(template.Sessions, templateSession)
+	}
+
+	if err := database.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Template saved", "template": template})
+}
+
+func findOwnedTemplate(c *gin.Context) (models.EventTemplate, models.User, bool) {
+	var template models.EventTemplate
+
+	user, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return template, user, false
+	}
+
+	if err := database.DB.Preload("Sessions").First(&template, c.Param("id")).Error; err != nil ||
+		!canManageEvent(user, models.Event{OrganizerID: template.OrganizerID}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return template, user, false
+	}
+
+	return template, user, true
+}
+
+func GetTemplates(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var templates []models.EventTemplate
+	if err := database.DB.Preload("Sessions").Where("organizer_id = ?", user.ID).Order("template_name").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func GetTemplateByID(c *gin.Context) {
+	template, _, ok := findOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func DeleteTemplate(c *gin.Context) {
+	template, _, ok := findOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	database.DB.Where("template_id = ?", template.ID).Delete(&models.EventTemplateSession{})
+	if err := database.DB.Delete(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// buat draft event baru dari template
+func CreateEventFromTemplate(c *gin.Context) {
+	template, user, ok := findOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	var input newScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.StartsAt == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "starts_at is required"})
+		return
+	}
+
+	event := models.Event{
+		Name:             template.Name,
+		Description:      template.Description,
+		Timezone:         template.Timezone,
+		LocationID:       template.LocationID,
+		Location:         template.Location,
+		Address:          template.Address,
+		VenueID:          template.VenueID,
+		Capacity:         template.Capacity,
+		Photo:            template.Photo,
+		Price:            template.Price,
+		CategoryID:       template.CategoryID,
+		Benefits:         template.Benefits,
+		Mode:             template.Mode,
+		Link:             template.Link,
+		RequiresApproval: template.RequiresApproval,
+		Visibility:       template.Visibility,
+		TransferPolicy:   template.TransferPolicy,
+	}
+	asNewDraft(&event, user)
+	if input.Name != "" {
+		event.Name = input.Name
+	}
+
+	duration := time.Duration(template.DurationMinutes) * time.Minute
+	start, end, err := input.schedule(event.TimeLocation(), time.Time{}, duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := event.SetSchedule(start, end); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	event.Status = event.ComputeStatus(time.Now())
+
+	var sessions []models.Session
+	for _, templateSession := range template.Sessions {
+		session := models.Session{
+			Timezone: templateSession.Timezone,
+			Speaker:  templateSession.Speaker,
+			Location: templateSession.Location,
+		}
+		if session.Timezone == "" {
+			session.Timezone = event.Timezone
+		}
+
+		sessionStart := start.Add(time.Duration(templateSession.OffsetMinutes) * time.Minute)
+		var sessionEnd time.Time
+		if templateSession.DurationMinutes > 0 {
+			sessionEnd = sessionStart.Add(time.Duration(templateSession.DurationMinutes) * time.Minute)
+		}
+		if err := session.SetSchedule(sessionStart, sessionEnd); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		sessions = append(sessions, session)
+	}
+
+	createDraft(c, event, sessions, "Event created from template")
+}
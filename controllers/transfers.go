@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gomail "gopkg.in/mail.v2"
+)
+
+func isValidTransferPolicy(policy string) bool {
+	return policy == "disabled" || policy == "registered_users" || policy == "anyone"
+}
+
+func generateTicketCode() (string, error) {
+	token, err := generateToken(5)
+	if err != nil {
+		return "", err
+	}
+	return "TKT-" + strings.ToUpper(token), nil
+}
+
+// batas akhir transfer, default sebelum hari pertama event
+func transferDeadline(event models.Event) (time.Time, error) {
+	if event.TransferDeadline != "" {
+		deadline, err := time.ParseInLocation(models.DateLayout, event.TransferDeadline, event.TimeLocation())
+		if err != nil {
+			return time.Time{}, err
+		}
+		return deadline.AddDate(0, 0, 1), nil
+	}
+	start, _, err := event.Period()
+	return start, err
+}
+
+// transfer tiket ke user lain atau ke alamat email lain
+func TransferRegistration(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terotorisasi"})
+		return
+	}
+
+	loggedInUser, ok := user.(models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Data user tidak valid"})
+		return
+	}
+
+	var registration models.Registration
+	if err := database.DB.Where("id = ? AND user_id = ? AND group_id IS NULL", c.Param("id"), loggedInUser.ID).First(&registration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pendaftaran tidak ditemukan"})
+		return
+	}
+
+	if registration.Status != "approved" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hanya pendaftaran yang sudah disetujui yang dapat ditransfer"})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.First(&event, registration.EventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event tidak ditemukan"})
+		return
+	}
+
+	if event.TransferPolicy == "" || event.TransferPolicy == "disabled" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Event ini tidak mengizinkan transfer tiket"})
+		return
+	}
+
+	deadline, err := transferDeadline(event)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer deadline format"})
+		return
+	}
+	if !time.Now().Before(deadline) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Batas waktu transfer tiket sudah lewat"})
+		return
+	}
+
+	var input struct {
+		Username string `json:"username"`
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Phone    string `json:"phone"`
+		Job      string `json:"job"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		return
+	}
+
+	if input.Name == "" || input.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama dan email penerima diperlukan"})
+		return
+	}
+
+	transfer := models.TicketTransfer{
+		RegistrationID: registration.ID,
+		EventID:        event.ID,
+		FromUserID:     loggedInUser.ID,
+		FromName:       registration.Name,
+		FromEmail:      registration.Email,
+		ToName:         input.Name,
+		ToEmail:        input.Email,
+		OldTicketCode:  registration.TicketCode,
+	}
+
+	if input.Username != "" {
+		var recipient models.User
+		if err := database.DB.Where("username = ?", input.Username).First(&recipient).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User penerima tidak ditemukan"})
+			return
+		}
+
+		if recipient.ID == loggedInUser.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak dapat mentransfer tiket ke diri sendiri"})
+			return
+		}
+
+		var existing models.Registration
+		if err := database.DB.Where("user_id = ? AND event_id = ? AND group_id IS NULL", recipient.ID, event.ID).First(&existing).Error; err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "User penerima sudah terdaftar untuk event ini"})
+			return
+		}
+
+		transfer.ToUserID = recipient.ID
+		registration.UserID = &recipient.ID
+		registration.Username = recipient.Username
+	} else if event.TransferPolicy != "anyone" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tiket event ini hanya dapat ditransfer ke user yang terdaftar"})
+		return
+	} else {
+		// penerima tanpa akun, tiket lepas dari pengirim dan hanya terikat ke email penerima
+		registration.UserID = nil
+		registration.Username = ""
+	}
+
+	ticketCode, err := generateTicketCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kode tiket"})
+		return
+	}
+	transfer.NewTicketCode = ticketCode
+
+	registration.Name = input.Name
+	registration.Email = input.Email
+	registration.PhoneNumber = input.Phone
+	registration.Job = input.Job
+	registration.TicketCode = ticketCode
+
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Save(&registration).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mentransfer tiket"})
+		return
+	}
+
+	if err := tx.Create(&transfer).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencatat transfer tiket"})
+		return
+	}
+
+	if transfer.FromEmail != "" {
+		if err := sendTicketTransferEmail(transfer.FromEmail, transfer.FromName, event.Name, transfer.ToName, transfer.OldTicketCode); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email ke pemilik tiket lama"})
+			return
+		}
+	}
+
+	if err := sendEmail(registration.Email, registration.Name, registration.PhoneNumber, registration.Job, event.Name, event.Location, formatEventDate(event), event.Description, event.Mode, event.Link, event.Address, registration.TicketCode); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email ke penerima tiket"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tiket berhasil ditransfer", "transfer": transfer})
+}
+
+// riwayat transfer tiket untuk satu event
+func GetEventTransfers(c *gin.Context) {
+	event, _, ok := findManagedEventParam(c, "event_id")
+	if !ok {
+		return
+	}
+
+	var transfers []models.TicketTransfer
+	if err := database.DB.Where("event_id = ?", event.ID).Order("created_at DESC").Find(&transfers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfers": transfers})
+}
+
+// cek keabsahan kode tiket saat check-in, hanya untuk event yang dikelola user login
+func VerifyTicket(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	code := strings.ToUpper(c.Param("code"))
+
+	var registration models.Registration
+	if err := database.DB.Preload("Event").Where("ticket_code = ?", code).First(&registration).Error; err == nil && canManageEvent(user, registration.Event) {
+		c.JSON(http.StatusOK, gin.H{
+			"valid":       registration.Status == "approved",
+			"status":      registration.Status,
+			"ticket_code": registration.TicketCode,
+			"event_id":    registration.EventID,
+			"event_name":  registration.Event.Name,
+			"name":        registration.Name,
+			"email":       registration.Email,
+		})
+		return
+	}
+
+	var transfer models.TicketTransfer
+	var event models.Event
+	if err := database.DB.Where("old_ticket_code = ?", code).First(&transfer).Error; err == nil &&
+		database.DB.First(&event, transfer.EventID).Error == nil && canManageEvent(user, event) {
+		c.JSON(http.StatusOK, gin.H{
+			"valid":          false,
+			"status":         "transferred",
+			"ticket_code":    code,
+			"event_id":       transfer.EventID,
+			"transferred_at": transfer.CreatedAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Tiket tidak ditemukan"})
+}
+
+func sendTicketTransferEmail(to, name, eventName, recipientName, oldTicketCode string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", os.Getenv("SMTP_USER"))
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Tiket Berhasil Ditransfer - "+eventName)
+
+	body := fmt.Sprintf(`
+		<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto;">
+			<h2 style="color: #333;">Halo, %s!</h2>
+			<p>Tiket Anda untuk event berikut telah berhasil ditransfer:</p>
+
+			<div style="background-color: #f8f9fa; padding: 20px; border-radius: 5px; margin: 15px 0;">
+				<h3 style="color: #007bff; margin-top: 0;">%s</h3>
+				<p style="color: #666;"><strong>Penerima:</strong> %s</p>
+				<p style="color: #666;"><strong>Kode Tiket Lama:</strong> %s</p>
+			</div>
+
+			<p style="color: #856404;">Kode tiket lama sudah tidak berlaku dan tidak dapat digunakan untuk masuk ke event.</p>
+
+			<div style="margin-top: 20px; padding-top: 20px; border-top: 1px solid #eee;">
+				<p style="color: #666; font-size: 14px;">
+					Jika Anda tidak merasa melakukan transfer ini, segera hubungi tim support kami di:<br>
+					Email: anjarriho081@gmail.com<br>
+					WhatsApp: +62 890 3333 4444
+				</p>
+			</div>
+		</div>
+	`, name, eventName, recipientName, oldTicketCode)
+
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer("smtp.gmail.com", 587, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"))
+	if err := d.DialAndSend(m); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// data turunan ikut dihapus dengan deleted_at yang sama persis dengan induknya,
+// supaya saat restore hanya data yang terhapus bersama induknya yang dikembalikan
+func softDeleteWhere(tx *gorm.DB, model interface{}, deletedAt time.Time, query string, args ...interface{}) error {
+	return tx.Model(model).Where(query, args...).Update("deleted_at", deletedAt).Error
+}
+
+func restoreWhere(tx *gorm.DB, model interface{}, deletedAt time.Time, query string, args ...interface{}) error {
+	return tx.Unscoped().Model(model).Where(query, args...).Where("deleted_at = ?", deletedAt).Update("deleted_at", nil).Error
+}
+
+// hapus event beserta sesi, pendaftaran dan ratingnya
+func softDeleteEvent(tx *gorm.DB, eventID uint, deletedAt time.Time) error {
+	for _, model := range []interface{}{&models.Session{}, &models.Registration{}, &models.Rating{}} {
+		if err := softDeleteWhere(tx, model, deletedAt, "event_id = ?", eventID); err != nil {
+			return err
+		}
+	}
+	return softDeleteWhere(tx, &models.Event{}, deletedAt, "id = ?", eventID)
+}
+
+// hapus user beserta pendaftaran dan ratingnya, kursi yang dipakai di event yang belum selesai dikembalikan.
+// mengembalikan id event yang kapasitasnya berubah
+func softDeleteUser(tx *gorm.DB, user models.User, deletedAt time.Time) ([]uint, error) {
+	var registrations []models.Registration
+	if err := tx.Preload("Event").Where("user_id = ? AND status = ?", user.ID, "approved").Find(&registrations).Error; err != nil {
+		return nil, err
+	}
+
+	var eventIDs []uint
+	now := time.Now()
+	for _, registration := range registrations {
+		if registration.Event.ComputeStatus(now) == models.StatusEnded {
+			continue
+		}
+		if err := tx.Model(&models.Event{}).Where("id = ?", registration.EventID).
+			UpdateColumn("remaining_capacity", gorm.Expr("remaining_capacity + 1")).Error; err != nil {
+			return nil, err
+		}
+		eventIDs = append(eventIDs, registration.EventID)
+	}
+
+	for _, model := range []interface{}{&models.Registration{}, &models.Rating{}} {
+		if err := softDeleteWhere(tx, model, deletedAt, "user_id = ?", user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return eventIDs, softDeleteWhere(tx, &models.User{}, deletedAt, "id = ?", user.ID)
+}
+
+// jumlah event yang memakai id di column. linkTables adalah tabel penghubung event dengan
+// kolom yang sama (misalnya event_categories), event di dalamnya ikut dihitung
+func countEvents(column string, id uint, linkTables ...string) (int64, error) {
+	condition, args := column+" = ?", []interface{}{id}
+	for _, table := range linkTables {
+		condition += " OR id IN (SELECT event_id FROM " + table + " WHERE " + column + " = ?)"
+		args = append(args, id)
+	}
+
+	var used int64
+	err := database.DB.Model(&models.Event{}).Where("("+condition+")", args...).Count(&used).Error
+	return used, err
+}
+
+// id pengganti dari reassign_to untuk data yang masih dipakai used kali oleh what.
+// tanpa reassign_to penghapusan ditolak, hasil 0 berarti tidak ada yang perlu dipindah
+func reassignTarget(c *gin.Context, id uint, used int64, what string, exists func(uint) bool) (uint, bool) {
+	if used == 0 {
+		return 0, true
+	}
+
+	reassignTo, err := strconv.Atoi(c.Query("reassign_to"))
+	if err != nil || reassignTo == 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Still used by " + what + ", pass reassign_to to move them first",
+			what:    used,
+		})
+		return 0, false
+	}
+
+	if uint(reassignTo) == id || !exists(uint(reassignTo)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassign_to"})
+		return 0, false
+	}
+	return uint(reassignTo), true
+}
+
+// pindahkan event (dan isi linkTables) dari id ke to
+func moveEvents(tx *gorm.DB, column string, id, to uint, linkTables ...string) error {
+	if err := tx.Model(&models.Event{}).Where(column+" = ?", id).Update(column, to).Error; err != nil {
+		return err
+	}
+	for _, table := range linkTables {
+		if err := tx.Exec("INSERT INTO "+table+" (event_id, "+column+") SELECT event_id, ? FROM "+table+
+			" WHERE "+column+" = ? ON CONFLICT DO NOTHING", to, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM "+table+" WHERE "+column+" = ?", id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pindahkan event ke category / location / organizer lain sebelum induknya dihapus.
+// tanpa reassign_to penghapusan ditolak kalau masih dipakai event
+func reassignEvents(c *gin.Context, column string, id uint, exists func(uint) bool, linkTables ...string) bool {
+	used, err := countEvents(column, id, linkTables...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check related events"})
+		return false
+	}
+
+	to, ok := reassignTarget(c, id, used, "events", exists)
+	if !ok || to == 0 {
+		return ok
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return moveEvents(tx, column, id, to, linkTables...)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reassign events"})
+		return false
+	}
+	return true
+}
+
+func RestoreEvent(c *gin.Context) {
+	var event models.Event
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&event, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted event not found"})
+		return
+	}
+
+	deletedAt := event.DeletedAt.Time
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Session{}, &models.Registration{}, &models.Rating{}} {
+			if err := restoreWhere(tx, model, deletedAt, "event_id = ?", event.ID); err != nil {
+				return err
+			}
+		}
+		return restoreWhere(tx, &models.Event{}, deletedAt, "id = ?", event.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event restored"})
+}
+
+func RestoreUser(c *gin.Context) {
+	var user models.User
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted user not found"})
+		return
+	}
+
+	deletedAt := user.DeletedAt.Time
+	var waitlisted int
+	var fullEvent string
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := restoreWhere(tx, &models.User{}, deletedAt, "id = ?", user.ID); err != nil {
+			return err
+		}
+		if err := restoreWhere(tx, &models.Rating{}, deletedAt, "user_id = ?", user.ID); err != nil {
+			return err
+		}
+
+		var registrations []models.Registration
+		if err := tx.Unscoped().Preload("Event").Where("user_id = ? AND deleted_at = ?", user.ID, deletedAt).Find(&registrations).Error; err != nil {
+			return err
+		}
+		if err := restoreWhere(tx, &models.Registration{}, deletedAt, "user_id = ?", user.ID); err != nil {
+			return err
+		}
+
+		// kursi dipesan ulang. kalau event sudah penuh, pendaftaran di event dengan persetujuan
+		// kembali menunggu persetujuan, di event tanpa persetujuan pemulihan ditolak
+		now := time.Now()
+		for _, registration := range registrations {
+			if registration.Status != "approved" || registration.Event.ComputeStatus(now) == models.StatusEnded {
+				continue
+			}
+			err := reserveSeats(tx, registration.EventID, 1)
+			if errors.Is(err, errEventFull) {
+				if !registration.Event.RequiresApproval {
+					fullEvent = registration.Event.Name
+					return err
+				}
+				if err := tx.Model(&models.Registration{}).Where("id = ?", registration.ID).Update("status", "pending").Error; err != nil {
+					return err
+				}
+				waitlisted++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errEventFull) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event " + fullEvent + " is full, the user's registration cannot be restored"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User restored", "pending_registrations": waitlisted})
+}
+
+func RestoreCategory(c *gin.Context) {
+	result := database.DB.Unscoped().Model(&models.Category{}).
+		Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).Update("deleted_at", nil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted category not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category restored"})
+}
+
+func RestoreLocation(c *gin.Context) {
+	result := database.DB.Unscoped().Model(&models.Location{}).
+		Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).Update("deleted_at", nil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore location"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted location not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Location restored"})
+}
+
+// daftar data yang sudah dihapus (belum di-purge) per jenis
+func GetTrash(c *gin.Context) {
+	var model, records interface{}
+	switch c.Param("type") {
+	case "events":
+		model, records = &models.Event{}, &[]models.Event{}
+	case "users":
+		model, records = &models.User{}, &[]struct {
+			ID        uint      `json:"id"`
+			Username  string    `json:"username"`
+			Role      string    `json:"role"`
+			DeletedAt time.Time `json:"deleted_at"`
+		}{}
+	case "categories":
+		model, records = &models.Category{}, &[]models.Category{}
+	case "locations":
+		model, records = &models.Location{}, &[]models.Location{}
+	case "venues":
+		model, records = &models.Venue{}, &[]models.Venue{}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type, must be events, users, categories, locations or venues"})
+		return
+	}
+
+	if err := database.DB.Unscoped().Model(model).Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
@@ -4,9 +4,11 @@ import (
 	"backend-event/models"
 	"net/http"
 	"backend-event/database"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 
@@ -133,7 +135,20 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&user).Error; err != nil {
+	// event milik organizer dipindahkan ke user lain lewat reassign_to
+	if !reassignEvents(c, "organizer_id", user.ID, func(id uint) bool {
+		var target models.User
+		return database.DB.First(&target, id).Error == nil && (target.Role == "admin" || target.Role == "organizer")
+	}) {
+		return
+	}
+
+	var eventIDs []uint
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		eventIDs, err = softDeleteUser(tx, user, time.Now())
+		return err
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete user",
 			"message": err.Error(),
@@ -141,6 +156,10 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
+	for _, eventID := range eventIDs {
+		UpdatePopularityScore(eventID)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User deleted successfully",
 		"user":    user,
@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// cek data venue, kota diambil dari location kalau belum diisi
+func prepareVenue(venue *models.Venue) error {
+	venue.Name = strings.TrimSpace(venue.Name)
+	if venue.Name == "" {
+		return errors.New("Name is required")
+	}
+	if (venue.Latitude == nil) != (venue.Longitude == nil) {
+		return errors.New("Latitude and longitude must be set together")
+	}
+	if venue.Latitude != nil && (*venue.Latitude < -90 || *venue.Latitude > 90 || *venue.Longitude < -180 || *venue.Longitude > 180) {
+		return errors.New("Invalid coordinates")
+	}
+	if venue.Capacity < 0 {
+		return errors.New("Invalid capacity")
+	}
+
+	if venue.LocationID != 0 {
+		var location models.Location
+		if err := database.DB.First(&location, venue.LocationID).Error; err != nil {
+			return errors.New("Invalid location ID")
+		}
+		if venue.City == "" {
+			venue.City = location.City
+		}
+	}
+
+	for i := range venue.Rooms {
+		if strings.TrimSpace(venue.Rooms[i].Name) == "" {
+			return errors.New("Room name is required")
+		}
+	}
+	return nil
+}
+
+// alamat dan kota event ikut venue yang dipakainya
+func syncVenueEvents(tx *gorm.DB, venueID uint) error {
+	return tx.Exec(`UPDATE events SET address = venues.address, location = venues.city, location_id = venues.location_id
+		FROM venues WHERE events.venue_id = venues.id AND venues.id = ?`, venueID).Error
+}
+
+// pasang venue dari field form venue_id ke event. venue_id kosong atau 0 melepas venue
+func applyVenueFromForm(c *gin.Context, event *models.Event) error {
+	value, ok := c.GetPostForm("venue_id")
+	if !ok {
+		return nil
+	}
+
+	venueID, err := strconv.Atoi(value)
+	if value == "" || (err == nil && venueID == 0) {
+		event.VenueID = nil
+		return nil
+	}
+
+	var venue models.Venue
+	if err != nil || database.DB.First(&venue, venueID).Error != nil {
+		return errors.New("Invalid venue ID")
+	}
+	if venue.Capacity > 0 && event.Capacity > venue.Capacity {
+		return errors.New("Capacity exceeds venue capacity")
+	}
+
+	event.VenueID = &venue.ID
+	event.LocationID = venue.LocationID
+	event.Location = venue.City
+	event.Address = venue.Address
+	if event.Mode == "online" && c.PostForm("mode") != "online" {
+		event.Mode = "offline"
+	}
+	return nil
+}
+
+// ruangan sesi harus milik venue event
+func findVenueRoom(event models.Event, value string) (*models.VenueRoom, error) {
+	roomID, err := strconv.Atoi(value)
+	if value == "" || (err == nil && roomID == 0) {
+		return nil, nil
+	}
+
+	var room models.VenueRoom
+	if err != nil || event.VenueID == nil ||
+		database.DB.Where("venue_id = ?", *event.VenueID).First(&room, roomID).Error != nil {
+		return nil, errors.New("Invalid room ID")
+	}
+	return &room, nil
+}
+
+func CreateVenue(c *gin.Context) {
+	var venue models.Venue
+	if err := c.ShouldBindJSON(&venue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	venue.ID = 0
+	if err := prepareVenue(&venue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&venue).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create venue"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Venue created", "data": venue})
+}
+
+// daftar venue, bisa difilter ?city=, ?location_id= dan ?q= (nama / alamat)
+func GetVenues(c *gin.Context) {
+	query := database.DB.Preload("Rooms").Order("name")
+	if city := c.Query("city"); city != "" {
+		query = query.Where("city ILIKE ?", city)
+	}
+	if locationID := c.Query("location_id"); locationID != "" {
+		query = query.Where("location_id = ?", locationID)
+	}
+	if q := c.Query("q"); q != "" {
+		query = query.Where("name ILIKE ? OR address ILIKE ?", "%"+q+"%", "%"+q+"%")
+	}
+
+	venues := []models.Venue{}
+	if err := query.Find(&venues).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch venues"})
+		return
+	}
+
+	c.JSON(http.StatusOK, venues)
+}
+
+func GetVenueByID(c *gin.Context) {
+	var venue models.Venue
+	if err := database.DB.Preload("Rooms").First(&venue, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venue not found"})
+		return
+	}
+
+	var events int64
+	database.DB.Model(&models.Event{}).Where("venue_id = ?", venue.ID).Count(&events)
+
+	c.JSON(http.StatusOK, gin.H{"data": venue, "events": events})
+}
+
+// ubah data venue, ruangan diatur lewat endpoint rooms
+func UpdateVenue(c *gin.Context) {
+	var venue models.Venue
+	if err := database.DB.First(&venue, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venue not found"})
+		return
+	}
+
+	venueID := venue.ID
+	if err := c.ShouldBindJSON(&venue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	venue.ID = venueID
+	venue.Rooms = nil
+
+	if err := prepareVenue(&venue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Rooms").Save(&venue).Error; err != nil {
+			return err
+		}
+		return syncVenueEvents(tx, venue.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update venue"})
+		return
+	}
+
+	database.DB.Where("venue_id = ?", venue.ID).Find(&venue.Rooms)
+	c.JSON(http.StatusOK, gin.H{"message": "Venue updated", "data": venue})
+}
+
+// venue yang masih dipakai event harus dipindah dulu dengan reassign_to
+func DeleteVenue(c *gin.Context) {
+	var venue models.Venue
+	if err := database.DB.First(&venue, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venue not found"})
+		return
+	}
+
+	used, err := countEvents("venue_id", venue.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check related events"})
+		return
+	}
+	to, ok := reassignTarget(c, venue.ID, used, "events", func(id uint) bool {
+		return database.DB.First(&models.Venue{}, id).Error == nil
+	})
+	if !ok {
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if to != 0 {
+			// ruangan venue lama tidak berlaku lagi di venue baru
+			if err := tx.Model(&models.Session{}).
+				Where("venue_room_id IN (?)", tx.Model(&models.VenueRoom{}).Select("id").Where("venue_id = ?", venue.ID)).
+				Update("venue_room_id", nil).Error; err != nil {
+				return err
+			}
+			if err := moveEvents(tx, "venue_id", venue.ID, to); err != nil {
+				return err
+			}
+			if err := syncVenueEvents(tx, to); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&venue).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete venue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Venue deleted"})
+}
+
+func RestoreVenue(c *gin.Context) {
+	result := database.DB.Unscoped().Model(&models.Venue{}).
+		Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).Update("deleted_at", nil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore venue"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted venue not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Venue restored"})
+}
+
+func bindVenueRoom(c *gin.Context, room *models.VenueRoom) bool {
+	roomID, venueID := room.ID, room.VenueID
+	if err := c.ShouldBindJSON(room); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return false
+	}
+	room.ID, room.VenueID = roomID, venueID
+
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Room name is required"})
+		return false
+	}
+	if room.Capacity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid capacity"})
+		return false
+	}
+	return true
+}
+
+func findVenueRoomByParam(c *gin.Context) (models.VenueRoom, bool) {
+	var room models.VenueRoom
+	if err := database.DB.Where("venue_id = ?", c.Param("id")).First(&room, c.Param("room_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return room, false
+	}
+	return room, true
+}
+
+func CreateVenueRoom(c *gin.Context) {
+	var venue models.Venue
+	if err := database.DB.First(&venue, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venue not found"})
+		return
+	}
+
+	room := models.VenueRoom{VenueID: venue.ID}
+	if !bindVenueRoom(c, &room) {
+		return
+	}
+
+	if err := database.DB.Create(&room).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Room created", "data": room})
+}
+
+func UpdateVenueRoom(c *gin.Context) {
+	room, ok := findVenueRoomByParam(c)
+	if !ok || !bindVenueRoom(c, &room) {
+		return
+	}
+
+	if err := database.DB.Save(&room).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Room updated", "data": room})
+}
+
+// sesi yang memakai ruangan ini dilepas dari ruangannya
+func DeleteVenueRoom(c *gin.Context) {
+	room, ok := findVenueRoomByParam(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Session{}).Where("venue_room_id = ?", room.ID).Update("venue_room_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&room).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Room deleted"})
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// konfigurasi full-text search untuk teks bahasa Indonesia. PostgreSQL di bawah 12 belum punya
+// stemmer indonesian, jadi dipakai "simple"
+var SearchConfig = "indonesian"
+
+// true kalau ekstensi pg_trgm tersedia untuk pencarian yang toleran salah ketik
+var Trigram bool
+
+// dokumen pencarian event: nama (A), pembicara sesi & venue (B), deskripsi (C), benefit (D).
+// tiap teks diindeks dengan konfigurasi Indonesia dan Inggris
+const searchFunctionsSQL = `
+CREATE OR REPLACE FUNCTION event_search_text(body text, weight "char") RETURNS tsvector AS $$
+	SELECT setweight(to_tsvector('%[1]s', COALESCE(body, '')), weight) ||
+		setweight(to_tsvector('english', COALESCE(body, '')), weight)
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION events_search_vector() RETURNS trigger AS $$
+DECLARE
+	speakers text;
+	venue text;
+BEGIN
+	SELECT string_agg(speaker, ' ') INTO speakers FROM sessions
+		WHERE event_id = NEW.id AND deleted_at IS NULL AND speaker <> '';
+	SELECT concat_ws(' ', name, address, city) INTO venue FROM venues WHERE id = NEW.venue_id;
+
+	NEW.search_vector := event_search_text(NEW.name, 'A') ||
+		event_search_text(concat_ws(' ', speakers, venue, NEW.location, NEW.address), 'B') ||
+		event_search_text(NEW.description, 'C') ||
+		event_search_text(NEW.benefits, 'D');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION sessions_refresh_event_search() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		UPDATE events SET search_vector = NULL WHERE id = OLD.event_id;
+	END IF;
+	IF TG_OP <> 'DELETE' AND (TG_OP = 'INSERT' OR NEW.event_id IS DISTINCT FROM OLD.event_id) THEN
+		UPDATE events SET search_vector = NULL WHERE id = NEW.event_id;
+	END IF;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION venues_refresh_event_search() RETURNS trigger AS $$
+BEGIN
+	UPDATE events SET search_vector = NULL WHERE venue_id = NEW.id;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+`
+
+const searchTriggersSQL = `
+DROP TRIGGER IF EXISTS events_search_vector ON events;
+CREATE TRIGGER events_search_vector BEFORE INSERT OR UPDATE OF name, description, benefits, location, address, venue_id, search_vector
+	ON events FOR EACH ROW EXECUTE PROCEDURE events_search_vector();
+
+DROP TRIGGER IF EXISTS sessions_refresh_event_search ON sessions;
+CREATE TRIGGER sessions_refresh_event_search AFTER INSERT OR UPDATE OF speaker, event_id, deleted_at OR DELETE
+	ON sessions FOR EACH ROW EXECUTE PROCEDURE sessions_refresh_event_search();
+
+DROP TRIGGER IF EXISTS venues_refresh_event_search ON venues;
+CREATE TRIGGER venues_refresh_event_search AFTER UPDATE OF name, address, city
+	ON venues FOR EACH ROW EXECUTE PROCEDURE venues_refresh_event_search();
+`
+
+// kolom search_vector di event diisi trigger, jadi semua jalur simpan (form, series, template,
+// rollback) ikut terindeks tanpa perlu diingat di tiap handler
+func setupSearch(db *gorm.DB) {
+	var hasIndonesian bool
+	db.Raw("SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'indonesian')").Scan(&hasIndonesian)
+	if !hasIndonesian {
+		SearchConfig = "simple"
+	}
+
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Println("pg_trgm is not available, search will not tolerate typos:", err)
+	} else {
+		Trigram = true
+	}
+
+	statements := []string{
+		"ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector",
+		"CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector)",
+		fmt.Sprintf(searchFunctionsSQL, SearchConfig),
+		searchTriggersSQL,
+	}
+	if Trigram {
+		statements = append(statements,
+			"CREATE INDEX IF NOT EXISTS idx_events_name_trgm ON events USING GIN (name gin_trgm_ops)",
+			"CREATE INDEX IF NOT EXISTS idx_sessions_speaker_trgm ON sessions USING GIN (speaker gin_trgm_ops)")
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Println("Failed to set up event search:", err)
+			return
+		}
+	}
+
+	// event lama yang belum punya dokumen pencarian
+	if err := db.Exec("UPDATE events SET search_vector = NULL WHERE search_vector IS NULL").Error; err != nil {
+		log.Println("Failed to backfill event search:", err)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"backend-event/models"
+	"backend-event/regions"
 )
 
 var DB *gorm.DB
@@ -34,11 +36,145 @@ func ConnectDatabase() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Event{}, &models.Registration{}, &models.Category{}, &models.Location{}, &models.Rating{}, &models.Session{})
+	err = db.AutoMigrate(&models.User{}, &models.Event{}, &models.Registration{}, &models.Category{}, &models.Location{}, &models.Rating{}, &models.Session{}, &models.Invitation{}, &models.RegistrationGroup{}, &models.TicketTransfer{}, &models.EventStatusLog{}, &models.EventSeries{}, &models.EventTemplate{}, &models.EventTemplateSession{}, &models.EventRevision{}, &models.Tag{}, &models.Venue{}, &models.VenueRoom{}, &models.Province{}, &models.Regency{}, &models.District{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	backfillTimestamps(db)
+	backfillEventCategories(db)
+	backfillCategorySlugs(db)
+	backfillVenues(db)
+
+	if err := regions.Seed(db); err != nil {
+		log.Fatal("Failed to seed regions:", err)
+	}
+	backfillLocationRegions(db)
+	setupSearch(db)
+	setupUpdatedAt(db)
+	setupTableChanges(db)
+
 	DB = db
 	fmt.Println("Database connected successfully")
 }
+
+// isi starts_at / ends_at untuk data lama yang masih pakai tanggal & jam berbentuk string
+func backfillTimestamps(db *gorm.DB) {
+	var events []models.Event
+	if err := db.Where("starts_at IS NULL AND date_start <> ''").Find(&events).Error; err != nil {
+		log.Println("Failed to load events for timestamp backfill:", err)
+		return
+	}
+
+	for _, event := range events {
+		if err := event.ScheduleFromLegacy(); err != nil {
+			log.Printf("Skipping timestamp backfill for event %d: %v", event.ID, err)
+			continue
+		}
+		db.Model(&models.Event{}).Where("id = ?", event.ID).UpdateColumns(map[string]interface{}{
+			"starts_at": event.StartsAt,
+			"ends_at":   event.EndsAt,
+		})
+	}
+
+	var sessions []models.Session
+	if err := db.Where("starts_at IS NULL AND date <> ''").Find(&sessions).Error; err != nil {
+		log.Println("Failed to load sessions for timestamp backfill:", err)
+		return
+	}
+
+	for _, session := range sessions {
+		if err := session.ScheduleFromLegacy(); err != nil {
+			log.Printf("Skipping timestamp backfill for session %d: %v", session.ID, err)
+			continue
+		}
+		db.Model(&models.Session{}).Where("id = ?", session.ID).UpdateColumns(map[string]interface{}{
+			"starts_at": session.StartsAt,
+			"ends_at":   session.EndsAt,
+		})
+	}
+}
+
+// kategori utama event lama dimasukkan ke daftar kategori event
+func backfillEventCategories(db *gorm.DB) {
+	if err := db.Exec(`INSERT INTO event_categories (event_id, category_id)
+		SELECT events.id, events.category_id FROM events
+		JOIN categories ON categories.id = events.category_id
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		log.Println("Failed to backfill event categories:", err)
+	}
+}
+
+// buat slug untuk kategori lama yang belum punya
+func backfillCategorySlugs(db *gorm.DB) {
+	var categories []models.Category
+	if err := db.Unscoped().Order("id").Find(&categories).Error; err != nil {
+		log.Println("Failed to load categories for slug backfill:", err)
+		return
+	}
+
+	taken := make(map[string]bool)
+	for _, category := range categories {
+		if category.Slug != "" {
+			taken[category.Slug] = true
+		}
+	}
+
+	for _, category := range categories {
+		if category.Slug != "" {
+			continue
+		}
+		base := models.Slugify(category.Name)
+		if base == "" {
+			base = "kategori"
+		}
+		slug := models.UniqueSlug(base, func(slug string) bool { return taken[slug] })
+		taken[slug] = true
+		db.Unscoped().Model(&models.Category{}).Where("id = ?", category.ID).UpdateColumn("slug", slug)
+	}
+}
+
+// alamat bebas di event lama dipindah ke venue, alamat yang sama di kota yang sama dipakai bersama
+func backfillVenues(db *gorm.DB) {
+	var events []models.Event
+	if err := db.Unscoped().Where("venue_id IS NULL AND address <> ''").Order("id").Find(&events).Error; err != nil {
+		log.Println("Failed to load events for venue backfill:", err)
+		return
+	}
+
+	for _, event := range events {
+		address := strings.TrimSpace(event.Address)
+
+		var venue models.Venue
+		err := db.Where("location_id = ? AND LOWER(address) = LOWER(?)", event.LocationID, address).First(&venue).Error
+		if err != nil {
+			venue = models.Venue{Name: address, Address: address, LocationID: event.LocationID, City: event.Location}
+			if err := db.Create(&venue).Error; err != nil {
+				log.Printf("Skipping venue backfill for event %d: %v", event.ID, err)
+				continue
+			}
+		}
+
+		db.Unscoped().Model(&models.Event{}).Where("id = ?", event.ID).UpdateColumn("venue_id", venue.ID)
+	}
+}
+
+// cocokkan nama kota di location lama dengan data wilayah
+func backfillLocationRegions(db *gorm.DB) {
+	var locations []models.Location
+	if err := db.Unscoped().Where("province_code = '' OR province_code IS NULL").Find(&locations).Error; err != nil {
+		log.Println("Failed to load locations for region backfill:", err)
+		return
+	}
+
+	for _, location := range locations {
+		provinceCode, regencyCode, err := regions.Match(db, location.City)
+		if err != nil || provinceCode == "" {
+			continue
+		}
+		db.Unscoped().Model(&models.Location{}).Where("id = ?", location.ID).UpdateColumns(map[string]interface{}{
+			"province_code": provinceCode,
+			"regency_code":  regencyCode,
+		})
+	}
+}
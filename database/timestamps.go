@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// tabel yang updated_at-nya diisi trigger
+var touchedTables = []string{"events", "categories", "locations", "ratings", "venues", "tags"}
+
+// tabel yang waktu perubahan terakhirnya dicatat di table_changes untuk Last-Modified.
+// termasuk tabel relasi dan hard delete (tag, purge) yang tidak menggeser updated_at
+var changeTrackedTables = []string{
+	"events", "categories", "locations", "ratings", "venues", "venue_rooms", "tags",
+	"sessions", "event_tags", "event_categories",
+}
+
+const touchFunctionSQL = `
+CREATE OR REPLACE FUNCTION touch_updated_at() RETURNS trigger AS $$
+BEGIN
+	NEW.updated_at := now();
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+`
+
+const changeTableSQL = `
+CREATE TABLE IF NOT EXISTS table_changes (
+	table_name text PRIMARY KEY,
+	changed_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION record_table_change() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO table_changes (table_name, changed_at) VALUES (TG_TABLE_NAME, now())
+	ON CONFLICT (table_name) DO UPDATE SET changed_at = EXCLUDED.changed_at;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+`
+
+// gorm hanya mengisi updated_at lewat Save / Updates. perubahan lewat UpdateColumn, Exec
+// (sisa kursi, skor popularitas, scheduler status) dan soft delete ikut tercatat lewat trigger
+func setupUpdatedAt(db *gorm.DB) {
+	if err := db.Exec(touchFunctionSQL).Error; err != nil {
+		log.Println("Failed to set up updated_at tracking:", err)
+		return
+	}
+
+	for _, table := range touchedTables {
+		statements := []string{
+			fmt.Sprintf("UPDATE %s SET updated_at = now() WHERE updated_at IS NULL", table),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_touch_updated_at ON %s", table, table),
+			fmt.Sprintf("CREATE TRIGGER %s_touch_updated_at BEFORE UPDATE ON %s FOR EACH ROW EXECUTE PROCEDURE touch_updated_at()", table, table),
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				log.Printf("Failed to set up updated_at tracking for %s: %v", table, err)
+				break
+			}
+		}
+	}
+}
+
+// trigger per statement (insert, update, delete, truncate) mencatat waktu perubahan tiap tabel,
+// jadi hapus permanen dan perubahan tabel relasi ikut menggeser Last-Modified
+func setupTableChanges(db *gorm.DB) {
+	if err := db.Exec(changeTableSQL).Error; err != nil {
+		log.Println("Failed to set up table change tracking:", err)
+		return
+	}
+
+	for _, table := range changeTrackedTables {
+		statements := []string{
+			fmt.Sprintf("INSERT INTO table_changes (table_name) VALUES ('%s') ON CONFLICT DO NOTHING", table),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_record_change ON %s", table, table),
+			fmt.Sprintf("CREATE TRIGGER %s_record_change AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON %s FOR EACH STATEMENT EXECUTE PROCEDURE record_table_change()", table, table),
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				log.Printf("Failed to set up change tracking for %s: %v", table, err)
+				break
+			}
+		}
+	}
+}
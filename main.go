@@ -5,7 +5,9 @@ import (
 	"github.com/gin-contrib/cors"
 	"backend-event/database"
 	"backend-event/routes"
+	"backend-event/scheduler"
 	"time"
+	_ "time/tzdata"
 )
 
 func main() {
@@ -23,6 +25,7 @@ func main() {
 	}))
 
 	database.ConnectDatabase()
+	scheduler.Start()
 
 	routes.AuthRoutes(r)
 
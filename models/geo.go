@@ -0,0 +1,59 @@
+package models
+
+import "math"
+
+const EarthRadiusKm = 6371.0
+
+// BoundingBox is a latitude/longitude rectangle in degrees.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// DistanceKm returns the great-circle (haversine) distance between two points.
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Pow(math.Sin(dLng/2), 2)
+	return EarthRadiusKm * 2 * math.Asin(math.Sqrt(math.Min(1, a)))
+}
+
+// BoundingBoxAround returns a box that contains every point within radiusKm
+// of the given point. Near the poles or across the antimeridian the longitude
+// range is widened to the whole globe.
+func BoundingBoxAround(lat, lng, radiusKm float64) BoundingBox {
+	dLat := radiusKm / EarthRadiusKm * 180 / math.Pi
+	box := BoundingBox{
+		MinLat: math.Max(-90, lat-dLat),
+		MaxLat: math.Min(90, lat+dLat),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	if cos := math.Cos(radians(lat)); box.MinLat > -90 && box.MaxLat < 90 && cos > 0 {
+		dLng := dLat / cos
+		if lng-dLng >= -180 && lng+dLng <= 180 {
+			box.MinLng, box.MaxLng = lng-dLng, lng+dLng
+		}
+	}
+	return box
+}
+
+func (b BoundingBox) Valid() bool {
+	return b.MinLat >= -90 && b.MaxLat <= 90 && b.MinLng >= -180 && b.MaxLng <= 180 &&
+		b.MinLat <= b.MaxLat && b.MinLng <= b.MaxLng
+}
+
+func (b BoundingBox) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+func (b BoundingBox) Center() (float64, float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
@@ -1,72 +1,306 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type User struct {
-	ID       uint   `gorm:"primaryKey" json:"id"`
-	Username string `gorm:"unique" json:"username"`
-	Password string `json:"password"`
-	Role     string `json:"role"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Username  string         `gorm:"unique" json:"username"`
+	Password  string         `json:"password"`
+	Role      string         `json:"role"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type Event struct {
-	ID                uint      `gorm:"primaryKey" json:"id"`
-	Name              string    `json:"name"`
-	Description       string    `json:"description"`
-	DateStart         string    `json:"datestart"`
-	DateEnd           string    `json:"dateend"`
-	Time              string    `json:"time"`
-	LocationID        uint      `json:"location_id"`
-	Location          string    `json:"location"`
-	Address           string    `json:"address"`
-	Capacity          int       `json:"capacity"`
-	RemainingCapacity int       `json:"remaining_capacity"`
-	Photo             string    `json:"photo"`
-	Price             string    `json:"price"`
-	CategoryID        uint      `json:"category_id"`
-	Benefits          string    `json:"benefits"`
-	Mode              string    `json:"mode"`
-	Link              string    `json:"link"`
-	Status            string    `json:"status"`
-	Sessions          []Session `gorm:"foreignKey:EventID" json:"sessions"`
-	PopularityScore   float64   `json:"popularity_score"`
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	DateStart         string         `json:"datestart"`
+	DateEnd           string         `json:"dateend"`
+	Time              string         `json:"time"`
+	StartsAt          *time.Time     `json:"starts_at"`
+	EndsAt            *time.Time     `json:"ends_at"`
+	Timezone          string         `gorm:"default:Asia/Jakarta" json:"timezone"`
+	LocationID        uint           `json:"location_id"`
+	Location          string         `json:"location"`
+	Address           string         `json:"address"`
+	VenueID           *uint          `gorm:"index" json:"venue_id"`
+	Capacity          int            `json:"capacity"`
+	RemainingCapacity int            `json:"remaining_capacity"`
+	Photo             string         `json:"photo"`
+	Price             string         `json:"price"`
+	CategoryID        uint           `json:"category_id"`
+	Benefits          string         `json:"benefits"`
+	Mode              string         `json:"mode"`
+	Link              string         `json:"link"`
+	Status            string         `json:"status"`
+	StatusReason      string         `json:"status_reason"`
+	RequiresApproval  bool           `json:"requires_approval"`
+	Visibility        string         `gorm:"default:public" json:"visibility"`
+	AccessCode        string         `json:"-"`
+	TransferPolicy    string         `gorm:"default:disabled" json:"transfer_policy"`
+	TransferDeadline  string         `json:"transfer_deadline"`
+	OrganizerID       *uint          `gorm:"index" json:"organizer_id"`
+	PublicationStatus string         `gorm:"default:published;index" json:"publication_status"`
+	PublishAt         *time.Time     `json:"publish_at"`
+	ReviewNote        string         `json:"review_note"`
+	SeriesID          *uint          `gorm:"index" json:"series_id,omitempty"`
+	OccurrenceDate    string         `json:"occurrence_date,omitempty"`
+	IsException       bool           `json:"is_exception,omitempty"`
+	Sessions          []Session      `gorm:"foreignKey:EventID" json:"sessions"`
+	Tags              []Tag          `gorm:"many2many:event_tags" json:"tags,omitempty"`
+	Categories        []Category     `gorm:"many2many:event_categories" json:"categories,omitempty"`
+	PopularityScore   float64        `json:"popularity_score"`
+	Version           int            `gorm:"not null;default:1" json:"version"`
+	UpdatedAt         time.Time      `gorm:"index" json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type EventSeries struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Name           string     `json:"name"`
+	OrganizerID    *uint      `gorm:"index" json:"organizer_id"`
+	RRule          string     `gorm:"column:rrule;not null" json:"rrule"`
+	ExDates        string     `gorm:"column:exdates" json:"exdates"`
+	Timezone       string     `gorm:"default:Asia/Jakarta" json:"timezone"`
+	StartsAt       time.Time  `json:"starts_at"`
+	GeneratedUntil *time.Time `json:"generated_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type EventTemplate struct {
+	ID               uint                   `gorm:"primaryKey" json:"id"`
+	TemplateName     string                 `gorm:"not null" json:"template_name"`
+	OrganizerID      *uint                  `gorm:"index" json:"organizer_id"`
+	Name             string                 `json:"name"`
+	Description      string                 `json:"description"`
+	Timezone         string                 `gorm:"default:Asia/Jakarta" json:"timezone"`
+	DurationMinutes  int                    `json:"duration_minutes"`
+	LocationID       uint                   `json:"location_id"`
+	Location         string                 `json:"location"`
+	Address          string                 `json:"address"`
+	VenueID          *uint                  `json:"venue_id"`
+	Capacity         int                    `json:"capacity"`
+	Photo            string                 `json:"photo"`
+	Price            string                 `json:"price"`
+	CategoryID       uint                   `json:"category_id"`
+	Benefits         string                 `json:"benefits"`
+	Mode             string                 `json:"mode"`
+	Link             string                 `json:"link"`
+	RequiresApproval bool                   `json:"requires_approval"`
+	Visibility       string                 `gorm:"default:public" json:"visibility"`
+	TransferPolicy   string                 `gorm:"default:disabled" json:"transfer_policy"`
+	Sessions         []EventTemplateSession `gorm:"foreignKey:TemplateID" json:"sessions"`
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+// sesi template disimpan relatif terhadap waktu mulai event
+type EventTemplateSession struct {
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	TemplateID      uint   `gorm:"not null;index" json:"template_id"`
+	OffsetMinutes   int    `json:"offset_minutes"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Timezone        string `json:"timezone,omitempty"`
+	Speaker         string `json:"speaker,omitempty"`
+	Location        string `json:"location,omitempty"`
 }
 
 type Session struct {
-	ID       uint   `json:"id" gorm:"primaryKey"`
-	EventID  uint   `json:"event_id"`
-	Date     string `json:"date"`
-	Time     string `json:"time,omitempty"`
-	Speaker  string `json:"speaker,omitempty"`
-	Location string `json:"location,omitempty"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	EventID     uint           `json:"event_id"`
+	Date        string         `json:"date"`
+	Time        string         `json:"time,omitempty"`
+	StartsAt    *time.Time     `json:"starts_at,omitempty"`
+	EndsAt      *time.Time     `json:"ends_at,omitempty"`
+	Timezone    string         `gorm:"default:Asia/Jakarta" json:"timezone,omitempty"`
+	Speaker     string         `json:"speaker,omitempty"`
+	Location    string         `json:"location,omitempty"`
+	VenueRoomID *uint          `gorm:"index" json:"venue_room_id,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type Registration struct {
-	ID            uint   `gorm:"primaryKey"`
-	UserID        uint   `json:"user_id"`
-	User          User   `gorm:"foreignKey:UserID"`
-	EventID       uint   `json:"event_id"`
-	Event         Event  `gorm:"foreignKey:EventID"`
-	Username      string `json:"username" `
-	Name          string `json:"name"`
-	Email         string `json:"email"`
-	PhoneNumber   string `json:"phone"`
-	Job           string `json:"job"`
-	PaymentMethod string `json:"payment_method"`
-	PaymentStatus string `json:"payment_status"`
+	ID            uint           `gorm:"primaryKey"`
+	UserID        *uint          `json:"user_id"`
+	User          User           `gorm:"foreignKey:UserID"`
+	EventID       uint           `json:"event_id"`
+	Event         Event          `gorm:"foreignKey:EventID"`
+	Username      string         `json:"username" `
+	Name          string         `json:"name"`
+	Email         string         `json:"email"`
+	PhoneNumber   string         `json:"phone"`
+	Job           string         `json:"job"`
+	PaymentMethod string         `json:"payment_method"`
+	PaymentStatus string         `json:"payment_status"`
+	Status        string         `gorm:"default:approved" json:"status"`
+	StatusReason  string         `json:"status_reason"`
+	GroupID       *uint          `json:"group_id"`
+	TicketCode    string         `gorm:"index:idx_registrations_ticket_code,unique,where:ticket_code <> ''" json:"ticket_code"`
+	RefundStatus  string         `json:"refund_status,omitempty"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type RegistrationGroup struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	EventID       uint      `gorm:"not null" json:"event_id"`
+	UserID        uint      `gorm:"not null" json:"user_id"`
+	Quantity      int       `gorm:"not null" json:"quantity"`
+	ContactName   string    `json:"contact_name"`
+	ContactEmail  string    `json:"contact_email"`
+	ContactPhone  string    `json:"contact_phone"`
+	PaymentMethod string    `json:"payment_method"`
+	PaymentStatus string    `json:"payment_status"`
+	RefundStatus  string    `json:"refund_status,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type EventRevision struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EventID    uint      `gorm:"not null;uniqueIndex:idx_event_revision" json:"event_id"`
+	Revision   int       `gorm:"not null;uniqueIndex:idx_event_revision" json:"revision"`
+	Snapshot   string    `gorm:"type:text" json:"-"`
+	Changes    string    `gorm:"type:text" json:"-"`
+	AuthorID   *uint     `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type EventStatusLog struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	EventID       uint       `gorm:"not null;index" json:"event_id"`
+	FromStatus    string     `json:"from_status"`
+	ToStatus      string     `json:"to_status"`
+	Reason        string     `json:"reason"`
+	OldStartsAt   *time.Time `json:"old_starts_at"`
+	OldEndsAt     *time.Time `json:"old_ends_at"`
+	NewStartsAt   *time.Time `json:"new_starts_at"`
+	NewEndsAt     *time.Time `json:"new_ends_at"`
+	ChangedBy     uint       `json:"changed_by"`
+	NotifiedCount int        `json:"notified_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type Invitation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventID   uint      `gorm:"not null" json:"event_id"`
+	Email     string    `gorm:"not null" json:"email"`
+	Name      string    `json:"name"`
+	Token     string    `gorm:"uniqueIndex" json:"-"`
+	Status    string    `gorm:"default:sent" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TicketTransfer struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	RegistrationID uint      `gorm:"not null" json:"registration_id"`
+	EventID        uint      `gorm:"not null" json:"event_id"`
+	FromUserID     uint      `json:"from_user_id"`
+	ToUserID       uint      `json:"to_user_id"`
+	FromName       string    `json:"from_name"`
+	FromEmail      string    `json:"from_email"`
+	ToName         string    `json:"to_name"`
+	ToEmail        string    `json:"to_email"`
+	OldTicketCode  string    `gorm:"index" json:"old_ticket_code"`
+	NewTicketCode  string    `json:"new_ticket_code"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
+// Category can be nested through ParentID. Slug is unique among categories
+// that have one; older rows get theirs backfilled at startup.
 type Category struct {
-	ID   uint   `gorm:"primaryKey" json:"id"`
-	Name string `json:"name"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `json:"name"`
+	Slug        string         `gorm:"uniqueIndex:idx_categories_slug,where:slug <> ''" json:"slug"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Color       string         `json:"color"`
+	SortOrder   int            `gorm:"default:0" json:"sort_order"`
+	ParentID    *uint          `gorm:"index" json:"parent_id"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Tag is a label attached to events. Curated tags are managed by admins,
+// free-form tags are created on the fly when an organizer uses a new name.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug"`
+	Curated   bool      `gorm:"default:false" json:"curated"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Location is a city used by events. The region codes point to the
+// administrative regions below; older rows may not have them yet.
 type Location struct {
-	ID   uint   `gorm:"primaryKey" json:"id"`
-	City string `gorm:"not null" json:"city"`
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	City         string         `gorm:"not null" json:"city"`
+	ProvinceCode string         `gorm:"index" json:"province_code"`
+	RegencyCode  string         `gorm:"index" json:"regency_code"`
+	DistrictCode string         `gorm:"index" json:"district_code"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Province, Regency (kabupaten/kota) and District (kecamatan) use the
+// Kemendagri codes, e.g. "31", "31.71" and "31.71.01".
+type Province struct {
+	Code string `gorm:"primaryKey" json:"code"`
+	Name string `gorm:"not null" json:"name"`
+}
+
+type Regency struct {
+	Code         string `gorm:"primaryKey" json:"code"`
+	ProvinceCode string `gorm:"index;not null" json:"province_code"`
+	Name         string `gorm:"not null" json:"name"`
+}
+
+type District struct {
+	Code        string `gorm:"primaryKey" json:"code"`
+	RegencyCode string `gorm:"index;not null" json:"regency_code"`
+	Name        string `gorm:"not null" json:"name"`
+}
+
+// Venue is a reusable place where events are held. LocationID points to the
+// city in Location; Address is copied to events that use the venue.
+type Venue struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	Name              string         `gorm:"not null" json:"name"`
+	Address           string         `json:"address"`
+	LocationID        uint           `gorm:"index" json:"location_id"`
+	City              string         `json:"city"`
+	Province          string         `json:"province"`
+	PostalCode        string         `json:"postal_code"`
+	Latitude          *float64       `gorm:"index:idx_venues_coordinates" json:"latitude"`
+	Longitude         *float64       `gorm:"index:idx_venues_coordinates" json:"longitude"`
+	Capacity          int            `json:"capacity"`
+	AccessibilityInfo string         `json:"accessibility_info"`
+	ParkingInfo       string         `json:"parking_info"`
+	Rooms             []VenueRoom    `gorm:"foreignKey:VenueID" json:"rooms"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type VenueRoom struct {
+	ID                uint   `gorm:"primaryKey" json:"id"`
+	VenueID           uint   `gorm:"index;not null" json:"venue_id"`
+	Name              string `gorm:"not null" json:"name"`
+	Floor             string `json:"floor"`
+	Capacity          int    `json:"capacity"`
+	AccessibilityInfo string `json:"accessibility_info"`
 }
 
 type Rating struct {
-	ID      uint `gorm:"primaryKey" json:"id"`
-	UserID  uint `gorm:"not null" json:"user_id"`
-	EventID uint `gorm:"not null" json:"event_id"`
-	Rating  int  `gorm:"not null" json:"rating"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserID    uint           `gorm:"not null" json:"user_id"`
+	EventID   uint           `gorm:"not null" json:"event_id"`
+	Rating    int            `gorm:"not null" json:"rating"`
+	UpdatedAt time.Time      `gorm:"index" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
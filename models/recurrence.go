@@ -0,0 +1,375 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var ErrInvalidRRule = errors.New("Invalid recurrence rule")
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RRuleDay is a BYDAY entry. Nth is only used with MONTHLY ("2TU" is the
+// second Tuesday, "-1FR" the last Friday); zero means every such weekday.
+type RRuleDay struct {
+	Nth     int
+	Weekday time.Weekday
+}
+
+// RRule is the subset of RFC 5545 recurrence rules supported for event
+// series: FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, COUNT, UNTIL, BYDAY and
+// BYMONTHDAY.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleDay
+	ByMonthDay []int
+}
+
+// ParseRRule parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE".
+// UNTIL may be a date or a timestamp and is read in loc.
+func ParseRRule(value string, loc *time.Location) (RRule, error) {
+	rule := RRule{Interval: 1}
+
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return rule, ErrInvalidRRule
+		}
+		key, val := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch key {
+		case "FREQ":
+			if val != FreqDaily && val != FreqWeekly && val != FreqMonthly {
+				return rule, ErrInvalidRRule
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, ErrInvalidRRule
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, ErrInvalidRRule
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(val, loc)
+			if err != nil {
+				return rule, ErrInvalidRRule
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				parsed, err := parseRRuleDay(day)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByDay = append(rule.ByDay, parsed)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return rule, ErrInvalidRRule
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		default:
+			return rule, ErrInvalidRRule
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, ErrInvalidRRule
+	}
+	return rule, nil
+}
+
+func parseRRuleUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	t, err := ParseTimestamp(value, loc)
+	if err == nil && isMidnight(t) {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t, err
+}
+
+func parseRRuleDay(value string) (RRuleDay, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return RRuleDay{}, ErrInvalidRRule
+	}
+
+	weekday, ok := rruleWeekdays[value[len(value)-2:]]
+	if !ok {
+		return RRuleDay{}, ErrInvalidRRule
+	}
+
+	day := RRuleDay{Weekday: weekday}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RRuleDay{}, ErrInvalidRRule
+		}
+		day.Nth = n
+	}
+	return day, nil
+}
+
+// Bounded reports whether the rule ends by itself through COUNT or UNTIL.
+func (r RRule) Bounded() bool {
+	return r.Count > 0 || r.Until != nil
+}
+
+// Occurrences expands the rule from start (the first occurrence, which also
+// fixes the time of day) up to horizon. Dates listed in exdates
+// ("2006-01-02") are skipped but still count towards COUNT, as in RFC 5545.
+func (r RRule) Occurrences(start, horizon time.Time, exdates map[string]bool) []time.Time {
+	var occurrences []time.Time
+	seen := 0
+
+	emit := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		if t.After(horizon) {
+			return false
+		}
+		if r.Count > 0 && seen >= r.Count {
+			return false
+		}
+		seen++
+		if !exdates[t.Format(DateLayout)] {
+			occurrences = append(occurrences, t)
+		}
+		return true
+	}
+
+	clock := start.Sub(AtClock(start, 0))
+
+	for period := 0; ; period++ {
+		candidates := r.periodDates(start, period)
+		if candidates == nil {
+			break
+		}
+
+		for _, date := range candidates {
+			if !emit(AtClock(date, clock)) {
+				return occurrences
+			}
+		}
+
+		// batas aman untuk aturan yang tidak pernah menghasilkan tanggal
+		if period > 5000 {
+			break
+		}
+	}
+	return occurrences
+}
+
+// periodDates returns the candidate dates of the given period (day, week or
+// month number counted from start), sorted.
+func (r RRule) periodDates(start time.Time, period int) []time.Time {
+	base := AtClock(start, 0)
+
+	switch r.Freq {
+	case FreqDaily:
+		return []time.Time{base.AddDate(0, 0, period*r.Interval)}
+
+	case FreqWeekly:
+		weekStart := base.AddDate(0, 0, -int(base.Weekday())+period*r.Interval*7)
+		if len(r.ByDay) == 0 {
+			return []time.Time{weekStart.AddDate(0, 0, int(base.Weekday()))}
+		}
+		var dates []time.Time
+		for _, day := range r.ByDay {
+			dates = append(dates, weekStart.AddDate(0, 0, int(day.Weekday)))
+		}
+		return sortedUnique(dates)
+
+	case FreqMonthly:
+		month := time.Date(base.Year(), base.Month()+time.Month(period*r.Interval), 1, 0, 0, 0, 0, base.Location())
+		daysInMonth := month.AddDate(0, 1, -1).Day()
+
+		var dates []time.Time
+		for _, n := range r.ByMonthDay {
+			day := n
+			if n < 0 {
+				day = daysInMonth + n + 1
+			}
+			if day >= 1 && day <= daysInMonth {
+				dates = append(dates, month.AddDate(0, 0, day-1))
+			}
+		}
+		for _, byDay := range r.ByDay {
+			dates = append(dates, monthWeekdays(month, daysInMonth, byDay)...)
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && base.Day() <= daysInMonth {
+			dates = append(dates, month.AddDate(0, 0, base.Day()-1))
+		}
+		if dates == nil {
+			return []time.Time{}
+		}
+		return sortedUnique(dates)
+	}
+	return nil
+}
+
+func monthWeekdays(month time.Time, daysInMonth int, byDay RRuleDay) []time.Time {
+	var matches []time.Time
+	for day := 1; day <= daysInMonth; day++ {
+		date := month.AddDate(0, 0, day-1)
+		if date.Weekday() == byDay.Weekday {
+			matches = append(matches, date)
+		}
+	}
+
+	switch {
+	case byDay.Nth > 0 && byDay.Nth <= len(matches):
+		return matches[byDay.Nth-1 : byDay.Nth]
+	case byDay.Nth < 0 && -byDay.Nth <= len(matches):
+		i := len(matches) + byDay.Nth
+		return matches[i : i+1]
+	case byDay.Nth == 0:
+		return matches
+	}
+	return nil
+}
+
+func sortedUnique(dates []time.Time) []time.Time {
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	unique := dates[:0]
+	for i, date := range dates {
+		if i == 0 || !date.Equal(dates[i-1]) {
+			unique = append(unique, date)
+		}
+	}
+	return unique
+}
+
+// String formats the rule back into RRULE syntax, UNTIL in UTC.
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, day := range r.ByDay {
+			code := ""
+			for name, weekday := range rruleWeekdays {
+				if weekday == day.Weekday {
+					code = name
+				}
+			}
+			if day.Nth != 0 {
+				code = strconv.Itoa(day.Nth) + code
+			}
+			days = append(days, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ShiftDays moves the BYDAY weekdays and BYMONTHDAY days of the rule by the
+// given number of days, for occurrences rescheduled to another date. A month
+// day that would leave its month is rejected with ErrInvalidRRule.
+func (r RRule) ShiftDays(days int) (RRule, error) {
+	if days == 0 {
+		return r, nil
+	}
+
+	shifted := r
+	shifted.ByDay = nil
+	for _, day := range r.ByDay {
+		day.Weekday = time.Weekday(((int(day.Weekday)+days)%7 + 7) % 7)
+		shifted.ByDay = append(shifted.ByDay, day)
+	}
+
+	shifted.ByMonthDay = nil
+	for _, day := range r.ByMonthDay {
+		n := day + days
+		if (day > 0 && (n < 1 || n > 31)) || (day < 0 && (n > -1 || n < -31)) {
+			return r, ErrInvalidRRule
+		}
+		shifted.ByMonthDay = append(shifted.ByMonthDay, n)
+	}
+	return shifted, nil
+}
+
+// ExDates parses a comma separated list of excluded dates.
+func ExDates(value string) map[string]bool {
+	dates := make(map[string]bool)
+	for _, date := range strings.Split(value, ",") {
+		if date = strings.TrimSpace(date); date != "" {
+			dates[date] = true
+		}
+	}
+	return dates
+}
+
+// WithUntil rewrites a rule so that it ends at until, replacing any COUNT or
+// UNTIL part.
+func WithUntil(value string, until time.Time) string {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(value), "RRULE:"), ";") {
+		key := strings.ToUpper(strings.SplitN(part, "=", 2)[0])
+		if part == "" || key == "COUNT" || key == "UNTIL" {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(append(parts, "UNTIL="+until.UTC().Format("20060102T150405Z")), ";")
+}
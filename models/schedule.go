@@ -0,0 +1,255 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultTimezone = "Asia/Jakarta"
+	DateLayout      = "2006-01-02"
+	ClockLayout     = "15:04"
+)
+
+var (
+	ErrInvalidStartDate = errors.New("Invalid start date format")
+	ErrInvalidEndDate   = errors.New("Invalid end date format")
+	ErrEndBeforeStart   = errors.New("End date cannot be before start date")
+)
+
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	return time.LoadLocation(name)
+}
+
+func timezoneOrDefault(name string) *time.Location {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ParseTimestamp accepts RFC3339 or a local "2006-01-02T15:04" / "2006-01-02 15:04"
+// interpreted in loc.
+func ParseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04", DateLayout} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid timestamp")
+}
+
+func parseClock(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(strings.Replace(value, ".", ":", 1))
+	clock, err := time.Parse(ClockLayout, value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, true
+}
+
+// ParseClockRange reads the free-text time field ("09:00", "09.00" or
+// "09:00 - 12:00"). end is zero when no end time is given.
+func ParseClockRange(value string) (start, end time.Duration, ok bool) {
+	parts := strings.SplitN(value, "-", 2)
+	start, ok = parseClock(parts[0])
+	if !ok {
+		return 0, 0, false
+	}
+	if len(parts) == 2 {
+		if parsed, valid := parseClock(parts[1]); valid && parsed > start {
+			end = parsed
+		}
+	}
+	return start, end, true
+}
+
+// AtClock returns the wall-clock time offset on the given local date.
+func AtClock(date time.Time, offset time.Duration) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()).Add(offset)
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+// legacy string fields for a typed range: date of start, last day (if
+// different) and "15:04" / "15:04 - 15:04"
+func legacyFields(start, end time.Time) (dateStart, dateEnd, clock string) {
+	dateStart = start.Format(DateLayout)
+
+	lastDay := end
+	if isMidnight(end) && end.After(start) {
+		lastDay = end.AddDate(0, 0, -1)
+	}
+	if lastDay.Format(DateLayout) != dateStart {
+		dateEnd = lastDay.Format(DateLayout)
+	}
+
+	if !isMidnight(start) {
+		clock = start.Format(ClockLayout)
+		if !isMidnight(end) {
+			clock += " - " + end.Format(ClockLayout)
+		}
+	}
+	return dateStart, dateEnd, clock
+}
+
+func (e *Event) TimeLocation() *time.Location {
+	return timezoneOrDefault(e.Timezone)
+}
+
+// ScheduleFromLegacy fills StartsAt/EndsAt from DateStart, DateEnd and Time
+// in the event timezone. Without an end time the event runs until the end of
+// its last day.
+func (e *Event) ScheduleFromLegacy() error {
+	loc := e.TimeLocation()
+
+	date, err := time.ParseInLocation(DateLayout, e.DateStart, loc)
+	if err != nil {
+		return ErrInvalidStartDate
+	}
+
+	endDate := date
+	if e.DateEnd != "" {
+		endDate, err = time.ParseInLocation(DateLayout, e.DateEnd, loc)
+		if err != nil {
+			return ErrInvalidEndDate
+		}
+		if endDate.Before(date) {
+			return ErrEndBeforeStart
+		}
+	}
+
+	start := date
+	end := endDate.AddDate(0, 0, 1)
+	if startClock, endClock, ok := ParseClockRange(e.Time); ok {
+		start = AtClock(date, startClock)
+		if endClock > 0 {
+			end = AtClock(endDate, endClock)
+		}
+	}
+
+	e.StartsAt = &start
+	e.EndsAt = &end
+	return nil
+}
+
+// SetSchedule stores a typed range and keeps the legacy string fields in sync.
+func (e *Event) SetSchedule(start, end time.Time) error {
+	if end.Before(start) {
+		return ErrEndBeforeStart
+	}
+
+	loc := e.TimeLocation()
+	start = start.In(loc)
+	end = end.In(loc)
+
+	e.StartsAt = &start
+	e.EndsAt = &end
+	e.DateStart, e.DateEnd, e.Time = legacyFields(start, end)
+	return nil
+}
+
+// Period returns the event start and end, falling back to the legacy fields
+// for rows that have not been migrated yet.
+func (e Event) Period() (time.Time, time.Time, error) {
+	if e.StartsAt == nil {
+		if err := e.ScheduleFromLegacy(); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	loc := e.TimeLocation()
+	start := e.StartsAt.In(loc)
+	if e.EndsAt == nil {
+		return start, AtClock(start, 0).AddDate(0, 0, 1), nil
+	}
+	return start, e.EndsAt.In(loc), nil
+}
+
+func (e *Event) BeforeSave(tx *gorm.DB) error {
+	if e.Timezone == "" {
+		e.Timezone = DefaultTimezone
+	}
+	if e.StartsAt == nil && e.DateStart != "" {
+		_ = e.ScheduleFromLegacy()
+	}
+	return nil
+}
+
+func (s *Session) TimeLocation() *time.Location {
+	return timezoneOrDefault(s.Timezone)
+}
+
+// ScheduleFromLegacy fills StartsAt/EndsAt from Date and Time. Sessions
+// without a time cover the whole day; sessions with only a start time have
+// no EndsAt.
+func (s *Session) ScheduleFromLegacy() error {
+	date, err := time.ParseInLocation(DateLayout, s.Date, s.TimeLocation())
+	if err != nil {
+		return ErrInvalidStartDate
+	}
+
+	start := date
+	end := date.AddDate(0, 0, 1)
+	s.EndsAt = &end
+	if startClock, endClock, ok := ParseClockRange(s.Time); ok {
+		start = AtClock(date, startClock)
+		s.EndsAt = nil
+		if endClock > 0 {
+			end = AtClock(date, endClock)
+			s.EndsAt = &end
+		}
+	}
+
+	s.StartsAt = &start
+	return nil
+}
+
+// SetSchedule stores a typed session range and keeps Date/Time in sync.
+// end may be zero when the session has no fixed end.
+func (s *Session) SetSchedule(start, end time.Time) error {
+	loc := s.TimeLocation()
+	start = start.In(loc)
+	s.StartsAt = &start
+	s.EndsAt = nil
+	s.Date = start.Format(DateLayout)
+	s.Time = ""
+	if !isMidnight(start) {
+		s.Time = start.Format(ClockLayout)
+	}
+
+	if !end.IsZero() {
+		if end.Before(start) {
+			return ErrEndBeforeStart
+		}
+		end = end.In(loc)
+		s.EndsAt = &end
+		if s.Time != "" && !isMidnight(end) {
+			s.Time += " - " + end.Format(ClockLayout)
+		}
+	}
+	return nil
+}
+
+func (s *Session) BeforeSave(tx *gorm.DB) error {
+	if s.Timezone == "" {
+		s.Timezone = DefaultTimezone
+	}
+	if s.StartsAt == nil && s.Date != "" {
+		_ = s.ScheduleFromLegacy()
+	}
+	return nil
+}
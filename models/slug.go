@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a name into a lowercase, dash separated slug
+// ("Seni & Budaya" becomes "seni-budaya").
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// UniqueSlug returns base, or base with a numeric suffix ("jazz-2") when
+// taken reports it is already used.
+func UniqueSlug(base string, taken func(string) bool) string {
+	slug := base
+	for i := 2; taken(slug); i++ {
+		slug = base + "-" + strconv.Itoa(i)
+	}
+	return slug
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	StatusUpcoming  = "upcoming"
+	StatusOngoing   = "ongoing"
+	StatusEnded     = "ended"
+	StatusCancelled = "cancelled"
+	StatusPostponed = "postponed"
+	StatusUnknown   = "unknown"
+)
+
+// publication workflow: draft -> in_review -> scheduled/published, or rejected
+// back to the organizer
+const (
+	PublicationDraft     = "draft"
+	PublicationInReview  = "in_review"
+	PublicationScheduled = "scheduled"
+	PublicationPublished = "published"
+	PublicationRejected  = "rejected"
+)
+
+// IsManualStatus reports whether status is set explicitly by an organizer
+// and must not be changed by the time-based transitions.
+func IsManualStatus(status string) bool {
+	return status == StatusCancelled || status == StatusPostponed
+}
+
+// ComputeStatus is the single source of truth for the event lifecycle
+// status at the given moment.
+func (e Event) ComputeStatus(now time.Time) string {
+	if IsManualStatus(e.Status) {
+		return e.Status
+	}
+
+	start, end, err := e.Period()
+	if err != nil {
+		return StatusUnknown
+	}
+
+	if now.Before(start) {
+		return StatusUpcoming
+	}
+	if now.Before(end) {
+		return StatusOngoing
+	}
+	return StatusEnded
+}
+
+// IsPublished reports whether the event is visible to the public.
+func (e Event) IsPublished() bool {
+	return e.PublicationStatus == "" || e.PublicationStatus == PublicationPublished
+}
@@ -0,0 +1,279 @@
+// Package regions imports the Indonesian administrative regions (provinsi,
+// kabupaten/kota, kecamatan) from CSV files.
+//
+// The bundled files in data/ are only a sample: every province and the
+// regencies of the larger cities, without districts. Point REGION_DATA_DIR at
+// a directory with the full Kemendagri dataset in the same format:
+//
+//	provinces.csv  code,name
+//	regencies.csv  code,province_code,name
+//	districts.csv  code,regency_code,name
+//
+// Seed refuses an incomplete dataset unless REGION_DATA_PARTIAL=true, so a
+// missing dataset shows up at startup instead of as unmatched cities.
+package regions
+
+import (
+	"backend-event/models"
+	"embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:embed data/*.csv
+var bundled embed.FS
+
+// Minimum size of a complete Kemendagri dataset (38 provinces, 514 regencies
+// and 7,277 districts in 2023), with some room for future changes.
+const (
+	minProvinces = 38
+	minRegencies = 500
+	minDistricts = 7000
+)
+
+// ErrIncomplete is returned by Seed when the dataset is smaller than the full
+// Kemendagri dataset.
+var ErrIncomplete = errors.New("region dataset is incomplete")
+
+// Counts is the number of rows read from each file.
+type Counts struct {
+	Provinces int `json:"provinces"`
+	Regencies int `json:"regencies"`
+	Districts int `json:"districts"`
+}
+
+// Complete reports whether the counts match a full Kemendagri dataset.
+func (c Counts) Complete() bool {
+	return c.Provinces >= minProvinces && c.Regencies >= minRegencies && c.Districts >= minDistricts
+}
+
+type dataset struct {
+	provinces []models.Province
+	regencies []models.Regency
+	districts []models.District
+}
+
+func (d dataset) counts() Counts {
+	return Counts{Provinces: len(d.provinces), Regencies: len(d.regencies), Districts: len(d.districts)}
+}
+
+// dataset from REGION_DATA_DIR, or the bundled files
+func dataFS() fs.FS {
+	if dir := os.Getenv("REGION_DATA_DIR"); dir != "" {
+		return os.DirFS(dir)
+	}
+	sub, _ := fs.Sub(bundled, "data")
+	return sub
+}
+
+func readCSV(fsys fs.FS, name string, columns int) ([][]string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = columns
+	reader.TrimLeadingSpace = true
+
+	var rows [][]string
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if line == 1 && row[0] == "code" {
+			continue
+		}
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+		}
+		rows = append(rows, row)
+	}
+}
+
+// kode anak harus diawali kode induknya, misalnya 31.71 di bawah 31
+func checkParent(name, code, parent string, parents map[string]bool) error {
+	if !parents[parent] || !strings.HasPrefix(code, parent+".") {
+		return fmt.Errorf("%s: %s has unknown parent %s", name, code, parent)
+	}
+	return nil
+}
+
+// baca dan cek semua file dataset
+func load() (dataset, error) {
+	var data dataset
+	fsys := dataFS()
+
+	provinceRows, err := readCSV(fsys, "provinces.csv", 2)
+	if err != nil {
+		return data, err
+	}
+	regencyRows, err := readCSV(fsys, "regencies.csv", 3)
+	if err != nil {
+		return data, err
+	}
+	districtRows, err := readCSV(fsys, "districts.csv", 3)
+	if err != nil {
+		return data, err
+	}
+
+	provinces := make([]models.Province, 0, len(provinceRows))
+	provinceCodes := make(map[string]bool)
+	for _, row := range provinceRows {
+		provinces = append(provinces, models.Province{Code: row[0], Name: row[1]})
+		provinceCodes[row[0]] = true
+	}
+
+	regencies := make([]models.Regency, 0, len(regencyRows))
+	regencyCodes := make(map[string]bool)
+	for _, row := range regencyRows {
+		if err := checkParent("regencies.csv", row[0], row[1], provinceCodes); err != nil {
+			return data, err
+		}
+		regencies = append(regencies, models.Regency{Code: row[0], ProvinceCode: row[1], Name: row[2]})
+		regencyCodes[row[0]] = true
+	}
+
+	districts := make([]models.District, 0, len(districtRows))
+	for _, row := range districtRows {
+		if err := checkParent("districts.csv", row[0], row[1], regencyCodes); err != nil {
+			return data, err
+		}
+		districts = append(districts, models.District{Code: row[0], RegencyCode: row[1], Name: row[2]})
+	}
+
+	return dataset{provinces: provinces, regencies: regencies, districts: districts}, nil
+}
+
+// upsert semua baris dalam satu transaksi
+func (d dataset) write(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		upsert := tx.Clauses(clause.OnConflict{UpdateAll: true})
+		if len(d.provinces) > 0 {
+			if err := upsert.CreateInBatches(&d.provinces, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(d.regencies) > 0 {
+			if err := upsert.CreateInBatches(&d.regencies, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(d.districts) > 0 {
+			if err := upsert.CreateInBatches(&d.districts, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Import reads the dataset and upserts it, so it can be run again after the
+// files are updated.
+func Import(db *gorm.DB) (Counts, error) {
+	data, err := load()
+	if err != nil {
+		return Counts{}, err
+	}
+	if err := data.write(db); err != nil {
+		return Counts{}, err
+	}
+	return data.counts(), nil
+}
+
+// jumlah baris yang sudah ada di database
+func storedCounts(db *gorm.DB) (Counts, error) {
+	var provinces, regencies, districts int64
+	if err := db.Model(&models.Province{}).Count(&provinces).Error; err != nil {
+		return Counts{}, err
+	}
+	if err := db.Model(&models.Regency{}).Count(&regencies).Error; err != nil {
+		return Counts{}, err
+	}
+	if err := db.Model(&models.District{}).Count(&districts).Error; err != nil {
+		return Counts{}, err
+	}
+	return Counts{Provinces: int(provinces), Regencies: int(regencies), Districts: int(districts)}, nil
+}
+
+// Seed imports the dataset when the stored row counts differ from the files,
+// so a newer or complete dataset is picked up on the next start. It returns
+// ErrIncomplete for a partial dataset unless REGION_DATA_PARTIAL=true.
+func Seed(db *gorm.DB) error {
+	data, err := load()
+	if err != nil {
+		return err
+	}
+
+	counts := data.counts()
+	if !counts.Complete() && os.Getenv("REGION_DATA_PARTIAL") != "true" {
+		return fmt.Errorf("%w (%d provinces, %d regencies, %d districts): set REGION_DATA_DIR to the full Kemendagri dataset, or REGION_DATA_PARTIAL=true to run with it anyway",
+			ErrIncomplete, counts.Provinces, counts.Regencies, counts.Districts)
+	}
+
+	stored, err := storedCounts(db)
+	if err != nil || stored == counts {
+		return err
+	}
+	return data.write(db)
+}
+
+var namePrefixes = []string{"kabupaten administrasi ", "kota administrasi ", "kabupaten ", "kab. ", "kab ", "kota ", "provinsi ", "dki ", "di "}
+
+// NormalizeName lowercases a region name and strips prefixes such as "Kota",
+// "Kabupaten" or "DKI", so "DKI Jakarta" and "Jakarta" compare equal.
+func NormalizeName(name string) string {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	for _, prefix := range namePrefixes {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return name
+}
+
+// Match finds the region of a free-text city name. A regency match wins over
+// a province match; ambiguous names (several regencies with the same
+// normalized name) are left unmatched.
+func Match(db *gorm.DB, city string) (provinceCode, regencyCode string, err error) {
+	name := NormalizeName(city)
+	if name == "" {
+		return "", "", nil
+	}
+
+	var regencies []models.Regency
+	if err := db.Find(&regencies).Error; err != nil {
+		return "", "", err
+	}
+	var matches []models.Regency
+	for _, regency := range regencies {
+		if NormalizeName(regency.Name) == name {
+			matches = append(matches, regency)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0].ProvinceCode, matches[0].Code, nil
+	}
+
+	var provinces []models.Province
+	if err := db.Find(&provinces).Error; err != nil {
+		return "", "", err
+	}
+	for _, province := range provinces {
+		if NormalizeName(province.Name) == name {
+			return province.Code, "", nil
+		}
+	}
+	return "", "", nil
+}
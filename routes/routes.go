@@ -17,14 +17,56 @@ func AuthRoutes(r *gin.Engine) {
 		router.GET("/user", controllers.GetAllUsers)
 		router.GET("/user/:id", controllers.GetUserById)
 		router.PUT("/user/:id", controllers.UpdateUser)
-		router.DELETE("user/:id", controllers.DeleteUser)
+		router.DELETE("user/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteUser)
 
 		// event
-		router.POST("/event", controllers.CreateEvent)
+		router.POST("/event", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CreateEvent)
 		router.GET("/event", controllers.GetAllEvents)
 		router.GET("/event/:id", controllers.GetEventByID)
-		router.PUT("/event/:id", controllers.UpdateEvent)
-		router.DELETE("/event/:id", controllers.DeleteEvent)
+		router.PUT("/event/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.UpdateEvent)
+		router.PATCH("/event/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.UpdateEvent)
+		router.DELETE("/event/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.DeleteEvent)
+		router.PUT("/event/:id/status", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.SetEventStatus)
+		router.POST("/event/:id/cancel", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CancelEvent)
+		router.POST("/event/:id/postpone", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.PostponeEvent)
+		router.GET("/event/:id/status-history", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetEventStatusHistory)
+
+		// draft, review & publikasi event
+		router.GET("/events/mine", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetMyEvents)
+		router.GET("/events/review", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.GetReviewQueue)
+		router.GET("/event/:id/preview", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.PreviewEvent)
+		router.POST("/event/:id/submit", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.SubmitEvent)
+		router.POST("/event/:id/publish", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.PublishEvent)
+		router.POST("/event/:id/reject", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RejectEvent)
+		router.POST("/event/:id/unpublish", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.UnpublishEvent)
+
+		// duplikat & template event
+		router.POST("/event/:id/duplicate", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.DuplicateEvent)
+		router.POST("/event/:id/template", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.SaveEventAsTemplate)
+		router.GET("/templates", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetTemplates)
+		router.GET("/templates/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetTemplateByID)
+		router.DELETE("/templates/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.DeleteTemplate)
+		router.POST("/templates/:id/events", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CreateEventFromTemplate)
+
+		// riwayat perubahan event
+		router.GET("/event/:id/revisions", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetEventRevisions)
+		router.GET("/event/:id/revisions/:revision", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetEventRevision)
+		router.POST("/event/:id/revisions/:revision/rollback", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RollbackEventRevision)
+
+		// data terhapus
+		router.GET("/trash/:type", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.GetTrash)
+		router.POST("/event/:id/restore", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RestoreEvent)
+		router.POST("/user/:id/restore", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RestoreUser)
+		router.POST("/categories/:id/restore", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RestoreCategory)
+		router.POST("/location/:id/restore", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RestoreLocation)
+		router.POST("/venues/:id/restore", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.RestoreVenue)
+
+		// event berulang
+		router.POST("/series", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CreateSeries)
+		router.GET("/series/:id", controllers.GetSeriesByID)
+		router.POST("/series/:id/extend", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.ExtendSeries)
+		router.PUT("/series/:id/occurrences/:event_id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.UpdateSeriesOccurrence)
+		router.DELETE("/series/:id/occurrences/:event_id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CancelSeriesOccurrence)
 
 		// daftar event
 		router.POST("/events/:event_id/register", middlewares.AuthMiddleware(), controllers.RegisterEvent)
@@ -32,20 +74,70 @@ func AuthRoutes(r *gin.Engine) {
 		router.GET("/events/:event_id/registered", controllers.GetEventRegistrants)
 
 		router.GET("/events/:event_id/check-registration", middlewares.AuthMiddleware(), controllers.CheckRegistration)
+		router.GET("/schedule", middlewares.AuthMiddleware(), controllers.GetMySchedule)
+
+		// pendaftaran grup
+		router.POST("/events/:event_id/groups", middlewares.AuthMiddleware(), controllers.RegisterGroup)
+		router.GET("/groups", middlewares.AuthMiddleware(), controllers.GetMyGroups)
+		router.GET("/groups/:id", middlewares.AuthMiddleware(), controllers.GetGroupByID)
+		router.PUT("/groups/:id/attendees/:registration_id", middlewares.AuthMiddleware(), controllers.UpdateGroupAttendee)
+
+		// transfer tiket
+		router.POST("/registrations/:id/transfer", middlewares.AuthMiddleware(), controllers.TransferRegistration)
+		router.GET("/events/:event_id/transfers", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetEventTransfers)
+		router.GET("/tickets/:code", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.VerifyTicket)
+
+		// persetujuan pendaftaran
+		router.PUT("/events/:event_id/registrations/:registration_id/approve", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.ApproveRegistration)
+		router.PUT("/events/:event_id/registrations/:registration_id/reject", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.RejectRegistration)
+		router.POST("/events/:event_id/registrations/approve", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.BulkApproveRegistrations)
+		router.POST("/events/:event_id/registrations/reject", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.BulkRejectRegistrations)
+
+		// undangan event private
+		router.POST("/events/:event_id/invitations", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CreateInvitations)
+		router.GET("/events/:event_id/invitations", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.GetInvitations)
+		router.DELETE("/events/:event_id/invitations/:invitation_id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.RevokeInvitation)
 
 		//kategori
 		router.POST("/categories", controllers.CreateCategory)
 		router.GET("/categories", controllers.GetCategories)
+		router.GET("/categories/tree", controllers.GetCategoryTree)
 		router.GET("/categories/:id", controllers.GetCategoryByID)
 		router.PUT("/categories/:id", controllers.UpdateCategory)
-		router.DELETE("/categories/:id", controllers.DeleteCategory)
+		router.DELETE("/categories/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteCategory)
+
+		// tag
+		router.GET("/tags", controllers.GetTags)
+		router.GET("/tags/:id", controllers.GetTagByID)
+		router.POST("/tags", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.CreateTag)
+		router.PUT("/tags/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.UpdateTag)
+		router.DELETE("/tags/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteTag)
+		router.POST("/tags/:id/merge", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.MergeTags)
 
 		//lokasi
 		router.POST("/location", controllers.CreateLocation)
 		router.GET("/location", controllers.GetAllLocations)
 		router.GET("/location/:id", controllers.GetLocationByID)
 		router.PUT("/location/:id", controllers.UpdateLocation)
-		router.DELETE("/location/:id", controllers.DeleteLocation)
+		router.DELETE("/location/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteLocation)
+
+		// wilayah
+		router.GET("/regions/provinces", controllers.GetProvinces)
+		router.GET("/regions/provinces/:code/regencies", controllers.GetRegencies)
+		router.GET("/regions/regencies/:code/districts", controllers.GetDistricts)
+		router.GET("/regions/event-counts", controllers.GetRegionEventCounts)
+		router.POST("/regions/import", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.ImportRegions)
+
+		// venue. organizer boleh menambah venue, perubahan venue dipakai bersama
+		// semua event yang memakainya jadi hanya admin
+		router.GET("/venues", controllers.GetVenues)
+		router.GET("/venues/:id", controllers.GetVenueByID)
+		router.POST("/venues", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "organizer"), controllers.CreateVenue)
+		router.PUT("/venues/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.UpdateVenue)
+		router.DELETE("/venues/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteVenue)
+		router.POST("/venues/:id/rooms", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.CreateVenueRoom)
+		router.PUT("/venues/:id/rooms/:room_id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.UpdateVenueRoom)
+		router.DELETE("/venues/:id/rooms/:room_id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteVenueRoom)
 
 		//rating
 		router.POST("/rating", middlewares.AuthMiddleware(), controllers.CreateRating)
@@ -54,6 +146,9 @@ func AuthRoutes(r *gin.Engine) {
 		router.DELETE("/rating/:id", middlewares.AuthMiddleware(), controllers.DeleteRating)
 
 		router.GET("/events/populars", controllers.GetPopularEvents)
+		router.GET("/events/nearby", controllers.GetNearbyEvents)
+		router.GET("/events/search", controllers.SearchEvents)
+		router.GET("/events/search/suggest", controllers.GetSearchSuggestions)
 		router.GET("/events/unregister",  middlewares.AuthMiddleware(), controllers.GetUnregisteredEvents)
 	}
 }
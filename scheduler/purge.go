@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const purgeInterval = 24 * time.Hour
+
+// lama data yang dihapus disimpan sebelum dihapus permanen
+func purgeRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("PURGE_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func runPurge() {
+	for {
+		if err := Purge(time.Now().Add(-purgeRetention())); err != nil {
+			log.Printf("Failed to purge deleted data: %v", err)
+		}
+		time.Sleep(purgeInterval)
+	}
+}
+
+// Purge menghapus permanen data yang di-soft delete sebelum waktu before,
+// termasuk data lain yang masih menunjuk ke event yang di-purge.
+func Purge(before time.Time) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		purgedEvents := tx.Unscoped().Model(&models.Event{}).Select("id").Where("deleted_at < ?", before)
+
+		for _, model := range []interface{}{&models.Session{}, &models.Rating{}, &models.Registration{}} {
+			if err := tx.Unscoped().Where("deleted_at < ? OR event_id IN (?)", before, purgedEvents).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, model := range []interface{}{&models.Invitation{}, &models.RegistrationGroup{}, &models.TicketTransfer{}, &models.EventStatusLog{}, &models.EventRevision{}} {
+			if err := tx.Where("event_id IN (?)", purgedEvents).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		purgedCategories := tx.Unscoped().Model(&models.Category{}).Select("id").Where("deleted_at < ?", before)
+		if err := tx.Exec("DELETE FROM event_tags WHERE event_id IN (?)", purgedEvents).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM event_categories WHERE event_id IN (?) OR category_id IN (?)", purgedEvents, purgedCategories).Error; err != nil {
+			return err
+		}
+
+		purgedVenues := tx.Unscoped().Model(&models.Venue{}).Select("id").Where("deleted_at < ?", before)
+		if err := tx.Where("venue_id IN (?)", purgedVenues).Delete(&models.VenueRoom{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.Event{}, &models.User{}, &models.Category{}, &models.Location{}, &models.Venue{}} {
+			if err := tx.Unscoped().Where("deleted_at < ?", before).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"backend-event/database"
+	"backend-event/models"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// jeda maksimum antar pengecekan, supaya perubahan yang terlewat tetap tersinkron
+const maxWait = 15 * time.Minute
+
+var wake = make(chan struct{}, 1)
+
+// Start menjalankan scheduler status event dan purge data terhapus di background.
+func Start() {
+	go run()
+	go runPurge()
+}
+
+// Wake membangunkan scheduler supaya menghitung ulang jadwal transisi berikutnya,
+// dipanggil setelah event dibuat atau jadwalnya diubah.
+func Wake() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func run() {
+	for {
+		now := time.Now()
+		if err := PublishScheduledEvents(now); err != nil {
+			log.Printf("Failed to publish scheduled events: %v", err)
+		}
+		if err := SyncEventStatuses(now); err != nil {
+			log.Printf("Failed to sync event statuses: %v", err)
+		}
+
+		wait := maxWait
+		if next, ok := nextTransition(now); ok && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-wake:
+			timer.Stop()
+		}
+	}
+}
+
+// PublishScheduledEvents menayangkan event terjadwal yang publish_at-nya sudah lewat.
+func PublishScheduledEvents(now time.Time) error {
+	return database.DB.Model(&models.Event{}).
+		Where("publication_status = ? AND publish_at <= ?", models.PublicationScheduled, now).
+		Update("publication_status", models.PublicationPublished).Error
+}
+
+// SyncEventStatuses menyamakan kolom status dengan jadwal event pada waktu now.
+// Status cancelled dan postponed tidak disentuh.
+func SyncEventStatuses(now time.Time) error {
+	automatic := []string{"", models.StatusUpcoming, models.StatusOngoing, models.StatusEnded, models.StatusUnknown}
+
+	if err := database.DB.Model(&models.Event{}).
+		Where("status IN ? AND starts_at > ?", automatic, now).
+		Update("status", models.StatusUpcoming).Error; err != nil {
+		return err
+	}
+
+	if err := database.DB.Model(&models.Event{}).
+		Where("status IN ? AND starts_at <= ? AND ends_at > ?", automatic, now, now).
+		Update("status", models.StatusOngoing).Error; err != nil {
+		return err
+	}
+
+	return database.DB.Model(&models.Event{}).
+		Where("status IN ? AND ends_at <= ?", automatic, now).
+		Update("status", models.StatusEnded).Error
+}
+
+// waktu transisi status terdekat setelah now
+func nextTransition(now time.Time) (time.Time, bool) {
+	var nextStart, nextEnd, nextPublish sql.NullTime
+
+	database.DB.Model(&models.Event{}).
+		Where("status = ? AND starts_at > ?", models.StatusUpcoming, now).
+		Select("MIN(starts_at)").Scan(&nextStart)
+
+	database.DB.Model(&models.Event{}).
+		Where("status IN ? AND ends_at > ?", []string{models.StatusUpcoming, models.StatusOngoing}, now).
+		Select("MIN(ends_at)").Scan(&nextEnd)
+
+	database.DB.Model(&models.Event{}).
+		Where("publication_status = ?", models.PublicationScheduled).
+		Select("MIN(publish_at)").Scan(&nextPublish)
+
+	var next time.Time
+	found := false
+	for _, candidate := range []sql.NullTime{nextStart, nextEnd, nextPublish} {
+		if candidate.Valid && (!found || candidate.Time.Before(next)) {
+			next = candidate.Time
+			found = true
+		}
+	}
+	return next, found
+}
@@ -0,0 +1,172 @@
+// Package serializers turns models into the JSON bodies returned by the API.
+//
+// The models keep their own json tags for binding and storage, which are not
+// always the keys clients see (Event.DateStart is "datestart" on the model but
+// "date_start" in responses). Controllers build responses from the types in
+// this package so every endpoint uses the same keys for the same data.
+package serializers
+
+import (
+	"backend-event/models"
+	"time"
+)
+
+// EventMeta is event data that lives in other tables: the city of the
+// location, the primary category name and the rating summary.
+type EventMeta struct {
+	City          string
+	Category      string
+	AverageRating float64
+	UniqueRaters  int64
+}
+
+// Event is the summary of an event used in listings. The sessions come from
+// event.Sessions and are only sent when requested with ?include=sessions.
+type Event struct {
+	ID                uint       `json:"id"`
+	Name              string     `json:"name"`
+	Description       string     `json:"description"`
+	DateStart         string     `json:"date_start"`
+	DateEnd           string     `json:"date_end"`
+	Time              string     `json:"time"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	Timezone          string     `json:"timezone"`
+	Location          string     `json:"location"`
+	Address           string     `json:"address"`
+	Capacity          int        `json:"capacity"`
+	RemainingCapacity int        `json:"remaining_capacity"`
+	Photo             string     `json:"photo"`
+	Price             string     `json:"price"`
+	Category          string     `json:"category"`
+	Status            string     `json:"status"`
+	StatusReason      string     `json:"status_reason"`
+	Mode              string     `json:"mode"`
+	AverageRating     float64    `json:"average_rating"`
+	UniqueRaters      int64      `json:"unique_raters"`
+	PopularityScore   float64    `json:"popularity_score"`
+	Categories        []Category `json:"categories"`
+	Tags              []Tag      `json:"tags"`
+	Sessions          []Session  `json:"sessions"`
+}
+
+// EventDetail is a single event with its sessions and venue.
+type EventDetail struct {
+	Event
+	LocationID       uint   `json:"location_id"`
+	CategoryID       uint   `json:"category_id"`
+	VenueID          *uint  `json:"venue_id"`
+	Venue            *Venue `json:"venue"`
+	Benefits         string `json:"benefits"`
+	Link             string `json:"link"`
+	RequiresApproval bool   `json:"requires_approval"`
+	Visibility       string `json:"visibility"`
+	TransferPolicy   string `json:"transfer_policy"`
+	TransferDeadline string `json:"transfer_deadline"`
+	Version          int    `json:"version"`
+}
+
+// ManagedEvent is the event as seen by its organizer, including the
+// publication workflow fields and the online link regardless of mode.
+type ManagedEvent struct {
+	EventDetail
+	PublicationStatus string     `json:"publication_status"`
+	PublishAt         *time.Time `json:"publish_at"`
+	ReviewNote        string     `json:"review_note"`
+	OrganizerID       *uint      `json:"organizer_id"`
+	SeriesID          *uint      `json:"series_id"`
+	OccurrenceDate    string     `json:"occurrence_date"`
+	IsException       bool       `json:"is_exception"`
+}
+
+// RegisteredEvent is an event in a user's registration list.
+type RegisteredEvent struct {
+	Event
+	Registration Registration `json:"registration"`
+}
+
+// NewEvent builds the listing summary. Events without a location are online
+// events; the status is computed at now.
+func NewEvent(event models.Event, meta EventMeta, now time.Time) Event {
+	location := meta.City
+	if event.LocationID == 0 {
+		location = "Online"
+	}
+
+	return Event{
+		ID:                event.ID,
+		Name:              event.Name,
+		Description:       event.Description,
+		DateStart:         event.DateStart,
+		DateEnd:           event.DateEnd,
+		Time:              event.Time,
+		StartsAt:          event.StartsAt,
+		EndsAt:            event.EndsAt,
+		Timezone:          event.Timezone,
+		Location:          location,
+		Address:           event.Address,
+		Capacity:          event.Capacity,
+		RemainingCapacity: event.RemainingCapacity,
+		Photo:             event.Photo,
+		Price:             event.Price,
+		Category:          meta.Category,
+		Status:            event.ComputeStatus(now),
+		StatusReason:      event.StatusReason,
+		Mode:              event.Mode,
+		AverageRating:     meta.AverageRating,
+		UniqueRaters:      meta.UniqueRaters,
+		PopularityScore:   event.PopularityScore,
+		Categories:        NewCategories(event.Categories),
+		Tags:              NewTags(event.Tags),
+		Sessions:          NewSessions(event.Sessions),
+	}
+}
+
+// NewEventDetail builds the public event page. The online link is only shown
+// for online events.
+func NewEventDetail(event models.Event, meta EventMeta, venue *models.Venue, now time.Time) EventDetail {
+	var link string
+	if event.Mode == "online" {
+		link = event.Link
+	}
+
+	return EventDetail{
+		Event:            NewEvent(event, meta, now),
+		LocationID:       event.LocationID,
+		CategoryID:       event.CategoryID,
+		VenueID:          event.VenueID,
+		Venue:            NewVenue(venue),
+		Benefits:         event.Benefits,
+		Link:             link,
+		RequiresApproval: event.RequiresApproval,
+		Visibility:       event.Visibility,
+		TransferPolicy:   event.TransferPolicy,
+		TransferDeadline: event.TransferDeadline,
+		Version:          event.Version,
+	}
+}
+
+// NewManagedEvent builds the organizer view of an event.
+func NewManagedEvent(event models.Event, meta EventMeta, venue *models.Venue, now time.Time) ManagedEvent {
+	detail := NewEventDetail(event, meta, venue, now)
+	detail.Link = event.Link
+
+	return ManagedEvent{
+		EventDetail:       detail,
+		PublicationStatus: event.PublicationStatus,
+		PublishAt:         event.PublishAt,
+		ReviewNote:        event.ReviewNote,
+		OrganizerID:       event.OrganizerID,
+		SeriesID:          event.SeriesID,
+		OccurrenceDate:    event.OccurrenceDate,
+		IsException:       event.IsException,
+	}
+}
+
+// NewRegisteredEvent builds an entry of a user's registration list.
+func NewRegisteredEvent(registration models.Registration, meta EventMeta, now time.Time) RegisteredEvent {
+	return RegisteredEvent{
+		Event:        NewEvent(registration.Event, meta, now),
+		Registration: NewRegistration(registration),
+	}
+}
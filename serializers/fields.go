@@ -0,0 +1,83 @@
+package serializers
+
+import "encoding/json"
+
+// EventRelations lists the relations of an event that can be requested with
+// ?include=, and the response keys each one fills.
+var EventRelations = map[string][]string{
+	"location":       {"location"},
+	"category":       {"category"},
+	"categories":     {"categories"},
+	"tags":           {"tags"},
+	"rating_summary": {"average_rating", "unique_raters"},
+	"sessions":       {"sessions"},
+}
+
+// EventDetailRelations adds the venue, which is only part of a single event.
+var EventDetailRelations = mergeRelations(EventRelations, map[string][]string{
+	"venue": {"venue"},
+})
+
+// RegisteredEventRelations are the relations of a registered event: the
+// event relations plus the registration itself.
+var RegisteredEventRelations = mergeRelations(EventRelations, map[string][]string{
+	"registration": {"registration"},
+})
+
+func mergeRelations(sets ...map[string][]string) map[string][]string {
+	merged := make(map[string][]string)
+	for _, set := range sets {
+		for name, keys := range set {
+			merged[name] = keys
+		}
+	}
+	return merged
+}
+
+// Project removes the keys in omit and, when fields is not empty, every key
+// not in fields except "id". v must encode to a JSON object or an array of
+// objects.
+func Project(v interface{}, fields, omit []string) (interface{}, error) {
+	if len(fields) == 0 && len(omit) == 0 {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []map[string]json.RawMessage
+	single := false
+	if err := json.Unmarshal(data, &objects); err != nil {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, err
+		}
+		objects, single = []map[string]json.RawMessage{object}, true
+	}
+
+	keep := make(map[string]bool, len(fields)+1)
+	for _, field := range fields {
+		keep[field] = true
+	}
+	keep["id"] = true
+
+	for _, object := range objects {
+		for _, key := range omit {
+			delete(object, key)
+		}
+		if len(fields) > 0 {
+			for key := range object {
+				if !keep[key] {
+					delete(object, key)
+				}
+			}
+		}
+	}
+
+	if single {
+		return objects[0], nil
+	}
+	return objects, nil
+}
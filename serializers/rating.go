@@ -0,0 +1,23 @@
+package serializers
+
+import "backend-event/models"
+
+// Rating is one user's rating of an event.
+type Rating struct {
+	ID      uint `json:"id"`
+	EventID uint `json:"event_id"`
+	UserID  uint `json:"user_id"`
+	Rating  int  `json:"rating"`
+}
+
+func NewRating(rating models.Rating) Rating {
+	return Rating{ID: rating.ID, EventID: rating.EventID, UserID: rating.UserID, Rating: rating.Rating}
+}
+
+func NewRatings(ratings []models.Rating) []Rating {
+	result := make([]Rating, 0, len(ratings))
+	for _, rating := range ratings {
+		result = append(result, NewRating(rating))
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package serializers
+
+import "backend-event/models"
+
+// Registration is one seat registered for an event.
+type Registration struct {
+	ID            uint   `json:"id"`
+	EventID       uint   `json:"event_id"`
+	UserID        *uint  `json:"user_id"`
+	Username      string `json:"username"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Phone         string `json:"phone"`
+	Job           string `json:"job"`
+	PaymentMethod string `json:"payment_method"`
+	PaymentStatus string `json:"payment_status"`
+	Status        string `json:"status"`
+	StatusReason  string `json:"status_reason"`
+	GroupID       *uint  `json:"group_id"`
+	TicketCode    string `json:"ticket_code"`
+	RefundStatus  string `json:"refund_status"`
+}
+
+func NewRegistration(registration models.Registration) Registration {
+	return Registration{
+		ID:            registration.ID,
+		EventID:       registration.EventID,
+		UserID:        registration.UserID,
+		Username:      registration.Username,
+		Name:          registration.Name,
+		Email:         registration.Email,
+		Phone:         registration.PhoneNumber,
+		Job:           registration.Job,
+		PaymentMethod: registration.PaymentMethod,
+		PaymentStatus: registration.PaymentStatus,
+		Status:        registration.Status,
+		StatusReason:  registration.StatusReason,
+		GroupID:       registration.GroupID,
+		TicketCode:    registration.TicketCode,
+		RefundStatus:  registration.RefundStatus,
+	}
+}
+
+// Registrant is a registration as listed to the event organizer. Payment and
+// refund data are left out.
+type Registrant struct {
+	ID           uint   `json:"id"`
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Job          string `json:"job"`
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason"`
+	GroupID      *uint  `json:"group_id"`
+}
+
+func NewRegistrants(registrations []models.Registration) []Registrant {
+	result := make([]Registrant, 0, len(registrations))
+	for _, registration := range registrations {
+		result = append(result, Registrant{
+			ID:           registration.ID,
+			Username:     registration.Username,
+			Name:         registration.Name,
+			Email:        registration.Email,
+			Phone:        registration.PhoneNumber,
+			Job:          registration.Job,
+			Status:       registration.Status,
+			StatusReason: registration.StatusReason,
+			GroupID:      registration.GroupID,
+		})
+	}
+	return result
+}
+
+// Attendee is one seat of a group registration. The payment belongs to the
+// group, so it is not repeated per seat.
+type Attendee struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	Job        string `json:"job"`
+	Status     string `json:"status"`
+	TicketCode string `json:"ticket_code"`
+}
+
+func NewAttendees(registrations []models.Registration) []Attendee {
+	result := make([]Attendee, 0, len(registrations))
+	for _, registration := range registrations {
+		result = append(result, Attendee{
+			ID:         registration.ID,
+			Name:       registration.Name,
+			Email:      registration.Email,
+			Phone:      registration.PhoneNumber,
+			Job:        registration.Job,
+			Status:     registration.Status,
+			TicketCode: registration.TicketCode,
+		})
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package serializers
+
+import (
+	"backend-event/models"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// go test ./serializers -update rewrites the golden files after an intended
+// change to the API contract.
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+var (
+	fixtureNow    = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	fixtureStart  = time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC)
+	fixtureEnd    = time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	fixtureUpdate = time.Date(2025, 2, 20, 8, 30, 0, 0, time.UTC)
+)
+
+func uintPtr(v uint) *uint { return &v }
+
+func fixtureSessions() []models.Session {
+	return []models.Session{
+		{ID: 11, EventID: 1, Date: "2025-03-10", Time: "09:00", StartsAt: &fixtureStart, EndsAt: &fixtureEnd, Timezone: "Asia/Jakarta", Speaker: "Rina Kusuma", Location: "Hall A", VenueRoomID: uintPtr(3)},
+	}
+}
+
+func fixtureEvent() models.Event {
+	return models.Event{
+		ID:                1,
+		Name:              "Go Meetup Jakarta",
+		Description:       "Berbagi pengalaman memakai Go di production.",
+		DateStart:         "2025-03-10",
+		DateEnd:           "2025-03-10",
+		Time:              "09:00",
+		StartsAt:          &fixtureStart,
+		EndsAt:            &fixtureEnd,
+		Timezone:          "Asia/Jakarta",
+		LocationID:        4,
+		Location:          "Jakarta",
+		Address:           "Jl. Sudirman No. 1",
+		Capacity:          100,
+		RemainingCapacity: 42,
+		Photo:             "/uploads/go-meetup.png",
+		Price:             "50000",
+		CategoryID:        2,
+		Status:            "upcoming",
+		Mode:              "offline",
+		Link:              "https://meet.example.com/go",
+		Benefits:          "Sertifikat, snack",
+		RequiresApproval:  true,
+		Visibility:        "public",
+		TransferPolicy:    "registered_users",
+		TransferDeadline:  "2025-03-08",
+		Version:           3,
+		PopularityScore:   12.5,
+		PublicationStatus: models.PublicationPublished,
+		PublishAt:         &fixtureUpdate,
+		OrganizerID:       uintPtr(7),
+		VenueID:           uintPtr(5),
+		Categories:        []models.Category{{ID: 2, Name: "Teknologi", Slug: "teknologi", SortOrder: 1, UpdatedAt: fixtureUpdate}},
+		Tags:              []models.Tag{{ID: 9, Name: "golang", Slug: "golang", Curated: true, CreatedAt: fixtureUpdate, UpdatedAt: fixtureUpdate}},
+		Sessions:          fixtureSessions(),
+	}
+}
+
+func fixtureMeta() EventMeta {
+	return EventMeta{City: "Jakarta", Category: "Teknologi", AverageRating: 4.67, UniqueRaters: 3}
+}
+
+func fixtureVenue() *models.Venue {
+	return &models.Venue{ID: 5, Name: "Gedung Serbaguna", Address: "Jl. Sudirman No. 1", LocationID: 4, City: "Jakarta", Capacity: 150, CreatedAt: fixtureUpdate, UpdatedAt: fixtureUpdate,
+		Rooms: []models.VenueRoom{{ID: 3, VenueID: 5, Name: "Hall A", Floor: "1", Capacity: 80}}}
+}
+
+func fixtureRegistration() models.Registration {
+	return models.Registration{
+		ID:            21,
+		UserID:        uintPtr(8),
+		EventID:       1,
+		Event:         fixtureEvent(),
+		Username:      "budi",
+		Name:          "Budi Santoso",
+		Email:         "budi@example.com",
+		PhoneNumber:   "08123456789",
+		Job:           "Backend Engineer",
+		PaymentMethod: "transfer",
+		PaymentStatus: "paid",
+		Status:        "approved",
+		GroupID:       uintPtr(6),
+		TicketCode:    "TKT-ABCDE",
+		RefundStatus:  "pending",
+	}
+}
+
+// bandingkan JSON v dengan testdata/<name>.json
+func assertGolden(t *testing.T, name string, v interface{}) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name+".json")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match the golden file\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	online := fixtureEvent()
+	online.LocationID = 0
+	online.Mode = "online"
+	online.Categories = nil
+	online.Tags = nil
+	online.Sessions = nil
+
+	ratingModel := models.Rating{ID: 31, UserID: 8, EventID: 1, Rating: 5, UpdatedAt: fixtureUpdate}
+	registrations := []models.Registration{fixtureRegistration()}
+
+	occurrence := fixtureEvent()
+	occurrence.OccurrenceDate = "2025-03-10"
+	series := models.EventSeries{ID: 4, Name: "Go Meetup Bulanan", OrganizerID: uintPtr(7), RRule: "FREQ=MONTHLY;BYDAY=2MO",
+		ExDates: "2025-04-14", Timezone: "Asia/Jakarta", StartsAt: fixtureStart, GeneratedUntil: &fixtureStart, CreatedAt: fixtureUpdate}
+
+	cases := []struct {
+		name string
+		v    interface{}
+	}{
+		{"event", NewEvent(fixtureEvent(), fixtureMeta(), fixtureNow)},
+		{"event_online", NewEvent(online, EventMeta{}, fixtureNow)},
+		{"event_detail", NewEventDetail(fixtureEvent(), fixtureMeta(), fixtureVenue(), fixtureNow)},
+		{"event_detail_online", NewEventDetail(online, EventMeta{}, nil, fixtureNow)},
+		{"managed_event", NewManagedEvent(fixtureEvent(), fixtureMeta(), fixtureVenue(), fixtureNow)},
+		{"registered_event", NewRegisteredEvent(fixtureRegistration(), fixtureMeta(), fixtureNow)},
+		{"session", NewSession(fixtureSessions()[0])},
+		{"sessions_empty", NewSessions(nil)},
+		{"registration", NewRegistration(fixtureRegistration())},
+		{"registrants", NewRegistrants(registrations)},
+		{"attendees", NewAttendees(registrations)},
+		{"rating", NewRating(ratingModel)},
+		{"series", NewSeries(series)},
+		{"occurrences", NewOccurrences([]models.Event{occurrence}, fixtureNow)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertGolden(t, tc.name, tc.v)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package serializers
+
+import (
+	"backend-event/models"
+	"time"
+)
+
+// Series is a recurring event; each occurrence is an event of its own.
+type Series struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	OrganizerID    *uint      `json:"organizer_id"`
+	RRule          string     `json:"rrule"`
+	ExDates        string     `json:"exdates"`
+	Timezone       string     `json:"timezone"`
+	StartsAt       time.Time  `json:"starts_at"`
+	GeneratedUntil *time.Time `json:"generated_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Occurrence is the summary of one event of a series.
+type Occurrence struct {
+	ID                uint       `json:"id"`
+	OccurrenceDate    string     `json:"occurrence_date"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	Status            string     `json:"status"`
+	Capacity          int        `json:"capacity"`
+	RemainingCapacity int        `json:"remaining_capacity"`
+	IsException       bool       `json:"is_exception"`
+}
+
+func NewSeries(series models.EventSeries) Series {
+	return Series{
+		ID:             series.ID,
+		Name:           series.Name,
+		OrganizerID:    series.OrganizerID,
+		RRule:          series.RRule,
+		ExDates:        series.ExDates,
+		Timezone:       series.Timezone,
+		StartsAt:       series.StartsAt,
+		GeneratedUntil: series.GeneratedUntil,
+		CreatedAt:      series.CreatedAt,
+	}
+}
+
+// NewOccurrences never returns nil; the status is computed at now.
+func NewOccurrences(events []models.Event, now time.Time) []Occurrence {
+	result := make([]Occurrence, 0, len(events))
+	for _, event := range events {
+		result = append(result, Occurrence{
+			ID:                event.ID,
+			OccurrenceDate:    event.OccurrenceDate,
+			StartsAt:          event.StartsAt,
+			EndsAt:            event.EndsAt,
+			Status:            event.ComputeStatus(now),
+			Capacity:          event.Capacity,
+			RemainingCapacity: event.RemainingCapacity,
+			IsException:       event.IsException,
+		})
+	}
+	return result
+}
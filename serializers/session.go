@@ -0,0 +1,44 @@
+package serializers
+
+import (
+	"backend-event/models"
+	"time"
+)
+
+// Session is one session of an event.
+type Session struct {
+	ID          uint       `json:"id"`
+	EventID     uint       `json:"event_id"`
+	Date        string     `json:"date"`
+	Time        string     `json:"time"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Timezone    string     `json:"timezone"`
+	Speaker     string     `json:"speaker"`
+	Location    string     `json:"location"`
+	VenueRoomID *uint      `json:"venue_room_id"`
+}
+
+func NewSession(session models.Session) Session {
+	return Session{
+		ID:          session.ID,
+		EventID:     session.EventID,
+		Date:        session.Date,
+		Time:        session.Time,
+		StartsAt:    session.StartsAt,
+		EndsAt:      session.EndsAt,
+		Timezone:    session.Timezone,
+		Speaker:     session.Speaker,
+		Location:    session.Location,
+		VenueRoomID: session.VenueRoomID,
+	}
+}
+
+// NewSessions never returns nil, so an event without sessions encodes as [].
+func NewSessions(sessions []models.Session) []Session {
+	result := make([]Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, NewSession(session))
+	}
+	return result
+}
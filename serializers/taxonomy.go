@@ -0,0 +1,72 @@
+package serializers
+
+import (
+	"backend-event/models"
+	"time"
+)
+
+// Category is a category an event is filed under.
+type Category struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	Color       string    `json:"color"`
+	SortOrder   int       `json:"sort_order"`
+	ParentID    *uint     `json:"parent_id"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewCategory(category models.Category) Category {
+	return Category{
+		ID:          category.ID,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+		Icon:        category.Icon,
+		Color:       category.Color,
+		SortOrder:   category.SortOrder,
+		ParentID:    category.ParentID,
+		UpdatedAt:   category.UpdatedAt,
+	}
+}
+
+// NewCategories never returns nil, so an event without categories encodes as [].
+func NewCategories(categories []models.Category) []Category {
+	result := make([]Category, 0, len(categories))
+	for _, category := range categories {
+		result = append(result, NewCategory(category))
+	}
+	return result
+}
+
+// Tag is a label attached to an event.
+type Tag struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Curated   bool      `json:"curated"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewTag(tag models.Tag) Tag {
+	return Tag{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Slug:      tag.Slug,
+		Curated:   tag.Curated,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	}
+}
+
+// NewTags never returns nil, so an event without tags encodes as [].
+func NewTags(tags []models.Tag) []Tag {
+	result := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, NewTag(tag))
+	}
+	return result
+}
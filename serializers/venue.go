@@ -0,0 +1,73 @@
+package serializers
+
+import (
+	"backend-event/models"
+	"time"
+)
+
+// Venue is the place an event is held, with its rooms.
+type Venue struct {
+	ID                uint        `json:"id"`
+	Name              string      `json:"name"`
+	Address           string      `json:"address"`
+	LocationID        uint        `json:"location_id"`
+	City              string      `json:"city"`
+	Province          string      `json:"province"`
+	PostalCode        string      `json:"postal_code"`
+	Latitude          *float64    `json:"latitude"`
+	Longitude         *float64    `json:"longitude"`
+	Capacity          int         `json:"capacity"`
+	AccessibilityInfo string      `json:"accessibility_info"`
+	ParkingInfo       string      `json:"parking_info"`
+	Rooms             []VenueRoom `json:"rooms"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+}
+
+// VenueRoom is a room of a venue that sessions can be held in.
+type VenueRoom struct {
+	ID                uint   `json:"id"`
+	VenueID           uint   `json:"venue_id"`
+	Name              string `json:"name"`
+	Floor             string `json:"floor"`
+	Capacity          int    `json:"capacity"`
+	AccessibilityInfo string `json:"accessibility_info"`
+}
+
+// NewVenue returns nil for a nil venue, so events without one encode
+// "venue": null.
+func NewVenue(venue *models.Venue) *Venue {
+	if venue == nil {
+		return nil
+	}
+
+	rooms := make([]VenueRoom, 0, len(venue.Rooms))
+	for _, room := range venue.Rooms {
+		rooms = append(rooms, VenueRoom{
+			ID:                room.ID,
+			VenueID:           room.VenueID,
+			Name:              room.Name,
+			Floor:             room.Floor,
+			Capacity:          room.Capacity,
+			AccessibilityInfo: room.AccessibilityInfo,
+		})
+	}
+
+	return &Venue{
+		ID:                venue.ID,
+		Name:              venue.Name,
+		Address:           venue.Address,
+		LocationID:        venue.LocationID,
+		City:              venue.City,
+		Province:          venue.Province,
+		PostalCode:        venue.PostalCode,
+		Latitude:          venue.Latitude,
+		Longitude:         venue.Longitude,
+		Capacity:          venue.Capacity,
+		AccessibilityInfo: venue.AccessibilityInfo,
+		ParkingInfo:       venue.ParkingInfo,
+		Rooms:             rooms,
+		CreatedAt:         venue.CreatedAt,
+		UpdatedAt:         venue.UpdatedAt,
+	}
+}